@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// batchWorkers bounds how many sub-queries of one batch_query run at once.
+const batchWorkers = 5
+
+const maxBatchQueries = 20
+
+type BatchQuery struct {
+	Key       string          `json:"key"`
+	Type      string          `json:"type"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type BatchResult struct {
+	Results map[string]json.RawMessage `json:"results"`
+	Errors  map[string]string          `json:"errors,omitempty"`
+}
+
+// batchHandlers maps batch_query sub-query types to the tool handler they run.
+var batchHandlers = map[string]func(*MCPServer, json.RawMessage) (any, *Error){
+	"stats":     (*MCPServer).execGetStats,
+	"pageviews": (*MCPServer).execGetPageViews,
+	"metrics":   (*MCPServer).execGetMetrics,
+}
+
+// mergeArguments fills arguments missing from a sub-query with the batch defaults.
+func mergeArguments(defaults map[string]json.RawMessage, args json.RawMessage) (json.RawMessage, error) {
+	merged := make(map[string]json.RawMessage, len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+
+	if len(args) > 0 {
+		var own map[string]json.RawMessage
+		if err := json.Unmarshal(args, &own); err != nil {
+			return nil, err
+		}
+		for k, v := range own {
+			merged[k] = v
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+// toolOutput extracts the JSON a tool handler rendered into its first text block.
+func toolOutput(result any) json.RawMessage {
+	response, ok := result.(map[string]any)
+	if !ok {
+		return nil
+	}
+	content, ok := response["content"].([]map[string]string)
+	if !ok || len(content) == 0 {
+		return nil
+	}
+
+	text := content[0]["text"]
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+	data, _ := json.Marshal(text)
+	return data
+}
+
+func validateBatchQueries(queries []BatchQuery) error {
+	if len(queries) == 0 {
+		return fmt.Errorf("queries must not be empty")
+	}
+	if len(queries) > maxBatchQueries {
+		return fmt.Errorf("at most %d queries per batch", maxBatchQueries)
+	}
+
+	seen := make(map[string]bool, len(queries))
+	for _, q := range queries {
+		if q.Key == "" {
+			return fmt.Errorf("every query needs a key")
+		}
+		if seen[q.Key] {
+			return fmt.Errorf("duplicate key %q", q.Key)
+		}
+		seen[q.Key] = true
+		if _, ok := batchHandlers[q.Type]; !ok {
+			return fmt.Errorf("query %q has unknown type %q (allowed: stats, pageviews, metrics)", q.Key, q.Type)
+		}
+	}
+	return nil
+}
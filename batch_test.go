@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMergeArguments(t *testing.T) {
+	defaults := map[string]json.RawMessage{
+		"website_id": json.RawMessage(`"abc123"`),
+		"start_date": json.RawMessage(`"2025-01-01"`),
+	}
+
+	merged, err := mergeArguments(defaults, json.RawMessage(`{"start_date": "2025-02-01", "limit": 5}`))
+	if err != nil {
+		t.Fatalf("mergeArguments failed: %v", err)
+	}
+
+	var args map[string]any
+	_ = json.Unmarshal(merged, &args)
+	if args["website_id"] != "abc123" || args["start_date"] != "2025-02-01" || args["limit"] != float64(5) {
+		t.Errorf("Unexpected merged arguments: %v", args)
+	}
+
+	if _, err := mergeArguments(defaults, json.RawMessage(`[1, 2]`)); err == nil {
+		t.Error("Expected error for non-object arguments")
+	}
+}
+
+func TestValidateBatchQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		queries []BatchQuery
+		wantErr bool
+	}{
+		{"valid", []BatchQuery{{Key: "a", Type: "stats"}, {Key: "b", Type: "metrics"}}, false},
+		{"empty", nil, true},
+		{"missing key", []BatchQuery{{Type: "stats"}}, true},
+		{"duplicate key", []BatchQuery{{Key: "a", Type: "stats"}, {Key: "a", Type: "pageviews"}}, true},
+		{"unknown type", []BatchQuery{{Key: "a", Type: "delete_website"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBatchQueries(tt.queries)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBatchQueries error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMCPServer_BatchQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/websites/abc123/stats":
+			_, _ = fmt.Fprint(w, `{"pageviews": 10, "visitors": 4}`)
+		case "/api/websites/abc123/metrics":
+			_, _ = fmt.Fprintf(w, `[{"x": %q, "y": 7}]`, r.URL.Query().Get("type"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "batch_query",
+		"arguments": map[string]any{
+			"defaults": map[string]string{"website_id": "abc123", "start_date": "2025-01-01", "end_date": "2025-01-31"},
+			"queries": []map[string]any{
+				{"key": "overview", "type": "stats"},
+				{"key": "top_pages", "type": "metrics", "arguments": map[string]any{"metric_type": "path"}},
+				{"key": "broken", "type": "stats", "arguments": map[string]any{"website_id": "../admin"}},
+			},
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	text := resp.Result.(map[string]any)["content"].([]map[string]string)[0]["text"]
+	var batch struct {
+		Results struct {
+			Overview Stats    `json:"overview"`
+			TopPages []Metric `json:"top_pages"`
+		} `json:"results"`
+		Errors map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(text), &batch); err != nil {
+		t.Fatalf("Failed to parse batch result: %v\n%s", err, text)
+	}
+
+	if batch.Results.Overview.PageViews != 10 {
+		t.Errorf("Expected overview pageviews 10, got %+v", batch.Results.Overview)
+	}
+	if len(batch.Results.TopPages) != 1 || batch.Results.TopPages[0].X != "path" {
+		t.Errorf("Expected metric_type to reach the metrics query, got %+v", batch.Results.TopPages)
+	}
+	if batch.Errors["broken"] != "Invalid website_id" {
+		t.Errorf("Expected broken query to report its own error, got %v", batch.Errors)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	comparePreviousPeriod = "previous_period"
+	comparePreviousYear   = "previous_year"
+	compareCustom         = "custom"
+)
+
+// umamiCompareModes maps get_stats compare modes to Umami's compare query parameter.
+var umamiCompareModes = map[string]string{
+	comparePreviousPeriod: "prev",
+	comparePreviousYear:   "yoy",
+}
+
+type PeriodStats struct {
+	StartDate        string  `json:"startDate"`
+	EndDate          string  `json:"endDate"`
+	PageViews        int     `json:"pageviews"`
+	Visitors         int     `json:"visitors"`
+	Visits           int     `json:"visits"`
+	Bounces          int     `json:"bounces"`
+	TotalTime        int     `json:"totaltime"`
+	BounceRate       float64 `json:"bounceRate"`
+	AvgVisitDuration float64 `json:"avgVisitDuration"`
+}
+
+type Delta struct {
+	Absolute   float64  `json:"absolute"`
+	Percentage *float64 `json:"percentage"`
+}
+
+type StatsReport struct {
+	Compare  string           `json:"compare"`
+	Current  PeriodStats      `json:"current"`
+	Previous PeriodStats      `json:"previous"`
+	Change   map[string]Delta `json:"change"`
+}
+
+// comparisonRange returns the millisecond range preceding [startMs, endMs]
+// for the given compare mode, mirroring how Umami shifts comparison periods.
+func comparisonRange(startMs, endMs, mode string) (string, string, error) {
+	start, err := strconv.ParseInt(startMs, 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid start_date")
+	}
+	end, err := strconv.ParseInt(endMs, 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end_date")
+	}
+
+	switch mode {
+	case comparePreviousPeriod:
+		diff := end - start
+		return strconv.FormatInt(start-diff, 10), strconv.FormatInt(end-diff, 10), nil
+	case comparePreviousYear:
+		prevStart := time.UnixMilli(start).UTC().AddDate(-1, 0, 0)
+		prevEnd := time.UnixMilli(end).UTC().AddDate(-1, 0, 0)
+		return strconv.FormatInt(prevStart.UnixMilli(), 10), strconv.FormatInt(prevEnd.UnixMilli(), 10), nil
+	default:
+		return "", "", fmt.Errorf("unknown compare mode %q", mode)
+	}
+}
+
+func newPeriodStats(startMs, endMs string, totals StatsComparison) PeriodStats {
+	period := PeriodStats{
+		StartDate:  reportDate(startMs),
+		EndDate:    reportDate(endMs),
+		PageViews:  totals.PageViews,
+		Visitors:   totals.Visitors,
+		Visits:     totals.Visits,
+		Bounces:    totals.Bounces,
+		TotalTime:  totals.TotalTime,
+		BounceRate: percentage(totals.Bounces, totals.Visits),
+	}
+	if totals.Visits > 0 {
+		period.AvgVisitDuration = math.Round(float64(totals.TotalTime)/float64(totals.Visits)*100) / 100
+	}
+	return period
+}
+
+func delta(current, previous float64) Delta {
+	d := Delta{Absolute: math.Round((current-previous)*100) / 100}
+	if previous != 0 {
+		pct := math.Round((current-previous)/previous*10000) / 100
+		d.Percentage = &pct
+	}
+	return d
+}
+
+func buildStatsReport(mode string, current, previous PeriodStats) StatsReport {
+	return StatsReport{
+		Compare:  mode,
+		Current:  current,
+		Previous: previous,
+		Change: map[string]Delta{
+			"pageviews":        delta(float64(current.PageViews), float64(previous.PageViews)),
+			"visitors":         delta(float64(current.Visitors), float64(previous.Visitors)),
+			"visits":           delta(float64(current.Visits), float64(previous.Visits)),
+			"bounces":          delta(float64(current.Bounces), float64(previous.Bounces)),
+			"totaltime":        delta(float64(current.TotalTime), float64(previous.TotalTime)),
+			"bounceRate":       delta(current.BounceRate, previous.BounceRate),
+			"avgVisitDuration": delta(current.AvgVisitDuration, previous.AvgVisitDuration),
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestComparisonRange(t *testing.T) {
+	start := normalizeDate("2026-03-08")
+	end := normalizeDate("2026-03-15")
+
+	prevStart, prevEnd, err := comparisonRange(start, end, comparePreviousPeriod)
+	if err != nil {
+		t.Fatalf("comparisonRange failed: %v", err)
+	}
+	if reportDate(prevStart) != "2026-03-01T00:00:00Z" || prevEnd != start {
+		t.Errorf("Expected previous week, got %s to %s", reportDate(prevStart), reportDate(prevEnd))
+	}
+
+	prevStart, prevEnd, err = comparisonRange(start, end, comparePreviousYear)
+	if err != nil {
+		t.Fatalf("comparisonRange failed: %v", err)
+	}
+	if reportDate(prevStart) != "2025-03-08T00:00:00Z" || reportDate(prevEnd) != "2025-03-15T00:00:00Z" {
+		t.Errorf("Expected same dates last year, got %s to %s", reportDate(prevStart), reportDate(prevEnd))
+	}
+
+	if _, _, err := comparisonRange("not-a-date", end, comparePreviousPeriod); err == nil {
+		t.Error("Expected error for unparseable start date")
+	}
+}
+
+func TestBuildStatsReport(t *testing.T) {
+	current := newPeriodStats("0", "1", StatsComparison{
+		PageViews: 300, Visitors: 120, Visits: 150, Bounces: 60, TotalTime: 9000,
+	})
+	previous := newPeriodStats("0", "1", StatsComparison{
+		PageViews: 200, Visitors: 100, Visits: 100, Bounces: 50, TotalTime: 0,
+	})
+
+	if current.BounceRate != 40 || current.AvgVisitDuration != 60 {
+		t.Errorf("Expected 40%% bounce rate and 60s visits, got %+v", current)
+	}
+
+	report := buildStatsReport(comparePreviousPeriod, current, previous)
+
+	pageviews := report.Change["pageviews"]
+	if pageviews.Absolute != 100 || pageviews.Percentage == nil || *pageviews.Percentage != 50 {
+		t.Errorf("Expected +100 (+50%%) pageviews, got %+v", pageviews)
+	}
+
+	bounceRate := report.Change["bounceRate"]
+	if bounceRate.Absolute != -10 || *bounceRate.Percentage != -20 {
+		t.Errorf("Expected bounce rate -10 points (-20%%), got %+v", bounceRate)
+	}
+
+	if report.Change["totaltime"].Percentage != nil {
+		t.Error("Expected nil percentage when previous value is 0")
+	}
+}
+
+func TestExecGetStats_CompareFallback(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("compare") == "prev" {
+			_, _ = w.Write([]byte(`{"pageviews": 300, "visitors": 120, "visits": 150, "bounces": 60, "totaltime": 9000}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"pageviews": 200, "visitors": 100, "visits": 100, "bounces": 50, "totaltime": 5000}`))
+	}))
+	defer server.Close()
+
+	s := &MCPServer{client: &UmamiClient{baseURL: server.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	args, _ := json.Marshal(map[string]any{
+		"website_id": "abc123",
+		"start_date": "2026-03-08",
+		"end_date":   "2026-03-15",
+		"compare":    "previous_period",
+	})
+	result, rpcErr := s.execGetStats(args)
+	if rpcErr != nil {
+		t.Fatalf("execGetStats failed: %v", rpcErr.Message)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("Expected a second request for the comparison range, got %d requests", len(calls))
+	}
+
+	text := result.(map[string]any)["content"].([]map[string]string)[0]["text"]
+	var report StatsReport
+	if err := json.Unmarshal([]byte(text), &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+
+	if report.Previous.PageViews != 200 || report.Change["pageviews"].Absolute != 100 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	if !strings.HasPrefix(report.Previous.StartDate, "2026-03-01") {
+		t.Errorf("Expected comparison to start 2026-03-01, got %s", report.Previous.StartDate)
+	}
+}
+
+func TestExecGetStats_CompareCustomRequiresRange(t *testing.T) {
+	s := &MCPServer{client: &UmamiClient{}}
+
+	args, _ := json.Marshal(map[string]any{
+		"website_id": "abc123",
+		"start_date": "2026-03-08",
+		"end_date":   "2026-03-15",
+		"compare":    "custom",
+	})
+	if _, rpcErr := s.execGetStats(args); rpcErr == nil || rpcErr.Code != -32602 {
+		t.Errorf("Expected -32602 for custom compare without range, got %v", rpcErr)
+	}
+}
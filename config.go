@@ -4,16 +4,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	UmamiURL string `yaml:"umami_url"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
-	APIKey   string `yaml:"api_key"`
-	TeamID   string `yaml:"team_id"`
+	UmamiURL    string `yaml:"umami_url"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	APIKey      string `yaml:"api_key"`
+	TeamID      string `yaml:"team_id"`
+	AllowWrites bool   `yaml:"allow_writes"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -46,6 +48,13 @@ func LoadConfig() (*Config, error) {
 	if teamID := os.Getenv("UMAMI_TEAM_ID"); teamID != "" {
 		config.TeamID = teamID
 	}
+	if allowWrites := os.Getenv("UMAMI_ALLOW_WRITES"); allowWrites != "" {
+		enabled, err := strconv.ParseBool(allowWrites)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UMAMI_ALLOW_WRITES: %w", err)
+		}
+		config.AllowWrites = enabled
+	}
 
 	if config.UmamiURL == "" {
 		return nil, fmt.Errorf("missing required configuration: UMAMI_URL")
@@ -93,3 +93,31 @@ func TestLoadConfig_TeamIDOptional(t *testing.T) {
 		t.Errorf("Expected empty TeamID, got '%s'", config.TeamID)
 	}
 }
+
+func TestLoadConfig_AllowWrites(t *testing.T) {
+	t.Setenv("UMAMI_URL", "https://test.com")
+	t.Setenv("UMAMI_USERNAME", "user")
+	t.Setenv("UMAMI_PASSWORD", "pass")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if config.AllowWrites {
+		t.Error("Expected writes to be disabled by default")
+	}
+
+	t.Setenv("UMAMI_ALLOW_WRITES", "true")
+	config, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() unexpected error: %v", err)
+	}
+	if !config.AllowWrites {
+		t.Error("Expected AllowWrites to be enabled by UMAMI_ALLOW_WRITES=true")
+	}
+
+	t.Setenv("UMAMI_ALLOW_WRITES", "maybe")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected error for invalid UMAMI_ALLOW_WRITES value")
+	}
+}
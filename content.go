@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// jsonContent renders v as an indented JSON text block, followed by any extra
+// text blocks, and keeps v as the tool's structured content.
+// processToolCall drops structuredContent for clients on older protocols.
+func jsonContent(v any, extra ...string) map[string]any {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return textContent(string(data), v, extra...)
+}
+
+// listContent is jsonContent for tools that return a list. Structured content
+// must be an object, so the list is nested under key there; the text block
+// keeps the bare list. A nil list is reported as empty.
+func listContent(key string, items any) map[string]any {
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	return textContent(string(data), map[string]any{key: items})
+}
+
+// textContent pairs a human-readable text block with its structured form.
+func textContent(text string, structured any, extra ...string) map[string]any {
+	content := []map[string]string{{
+		"type": "text",
+		"text": text,
+	}}
+	for _, e := range extra {
+		content = append(content, map[string]string{
+			"type": "text",
+			"text": e,
+		})
+	}
+	return map[string]any{
+		"content":           content,
+		"structuredContent": structured,
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+const (
+	fixtureWebsiteID = "f1e2d3c4-0000-0000-0000-000000000001"
+	fixtureSessionID = "0a0b0c0d-0000-0000-0000-000000000002"
+	fixtureTeamID    = "7e7e7e7e-0000-0000-0000-000000000003"
+	fixtureSegmentID = "5e5e5e5e-0000-0000-0000-000000000004"
+)
+
+// outputFixtureServer answers every Umami endpoint the tools call with a
+// small, fully populated response, keyed by "METHOD path" or path.
+func outputFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	website := `{"id": "` + fixtureWebsiteID + `", "name": "Blog", "domain": "blog.example.com",` +
+		`"shareId": "abc123", "createdAt": "2025-01-01T00:00:00Z"}`
+	session := `{"id": "` + fixtureSessionID + `", "websiteId": "` + fixtureWebsiteID + `",` +
+		`"browser": "firefox", "os": "Linux", "device": "desktop", "country": "DE",` +
+		`"firstAt": "2025-01-01T10:00:00Z", "lastAt": "2025-01-01T10:15:00Z", "visits": 1, "views": 4,` +
+		`"distinctId": "user-42"}`
+	segment := `{"id": "` + fixtureSegmentID + `", "type": "segment", "name": "Germany",` +
+		`"parameters": {"filters": [{"name": "country", "operator": "eq", "value": "DE"}]},` +
+		`"createdAt": "2025-01-01T00:00:00Z"}`
+	site := "/api/websites/" + fixtureWebsiteID
+
+	fixtures := map[string]string{
+		"GET /api/websites":  `{"data": [` + website + `]}`,
+		"POST /api/websites": website,
+		"DELETE " + site:     `ok`,
+		site:                 website,
+		site + "/reset":      `ok`,
+		site + "/stats":      `{"pageviews": 120, "visitors": 40, "visits": 50, "bounces": 20, "totaltime": 3600}`,
+		site + "/pageviews": `{"pageviews": [{"x": "2025-01-06 09:00:00", "y": 12}],` +
+			`"sessions": [{"x": "2025-01-06 09:00:00", "y": 4}]}`,
+		site + "/metrics": `[{"x": "/blog/post1", "y": 150}, {"x": "/about", "y": 80}]`,
+		site + "/active":  `{"x": 10}`,
+		site + "/event-data/events": `[{"eventName": "signup", "propertyName": "plan",` +
+			`"dataType": 1, "total": 42}]`,
+		site + "/events/series":                `[{"x": "signup", "t": "2025-01-01 10:00:00", "y": 3}]`,
+		site + "/event-data/values":            `[{"value": "pro", "total": 30}, {"value": "free", "total": 12}]`,
+		site + "/sessions":                     `{"data": [` + session + `], "count": 1, "page": 1, "pageSize": 20}`,
+		site + "/session-data/properties":      `[{"propertyName": "plan", "total": 3}]`,
+		site + "/session-data/values":          `[{"value": "pro", "total": 2}, {"value": "free", "total": 1}]`,
+		site + "/segments":                     `{"data": [` + segment + `]}`,
+		site + "/segments/" + fixtureSegmentID: segment,
+		site + "/sessions/" + fixtureSessionID + "/activity": `[{"createdAt": "2025-01-01T10:00:00Z",` +
+			`"urlPath": "/", "eventType": 1, "visitId": "v1"}]`,
+		site + "/sessions/" + fixtureSessionID + "/properties": `[{"dataKey": "plan", "dataType": 1, "stringValue": "pro"}]`,
+		"/api/realtime/" + fixtureWebsiteID: `{"countries": {"DE": 2}, "urls": {"/": 4}, "referrers": {"google.com": 3},` +
+			`"events": [{"__type": "pageview", "createdAt": "2025-01-01T10:01:00Z", "urlPath": "/"}],` +
+			`"totals": {"views": 10, "visitors": 7, "events": 1, "countries": 1}}`,
+		"/api/reports/funnel": `[{"type": "path", "value": "/pricing", "visitors": 200},` +
+			`{"type": "path", "value": "/signup", "visitors": 50}]`,
+		"/api/reports/retention": `[{"date": "2025-01-06T00:00:00Z", "day": 0, "visitors": 80, "returnVisitors": 80},` +
+			`{"date": "2025-01-06T00:00:00Z", "day": 1, "visitors": 80, "returnVisitors": 12}]`,
+		"/api/reports/journey": `[{"items": ["/", "/pricing", "/signup", null], "count": 30}]`,
+		"/api/reports/utm":     `{"utm_source": {"newsletter": 9}, "utm_medium": {"email": 9}}`,
+		"/api/reports/attribution": `{"referrer": [{"name": "google.com", "value": 14}], "paidAds": [],` +
+			`"utm_source": [{"name": "newsletter", "value": 9}]}`,
+		"/api/reports/goal": `{"num": 30, "total": 600}`,
+		"/api/reports/revenue": `{"chart": [{"x": "checkout", "t": "2025-01-01", "y": 100}],` +
+			`"country": [{"name": "DE", "value": 100}], "referrer": [{"name": "google.com", "value": 100}],` +
+			`"total": {"sum": 100, "count": 1, "unique_count": 1, "average": 100}}`,
+		"/api/me/teams": `{"data": [{"id": "` + fixtureTeamID + `", "name": "Marketing",` +
+			`"createdAt": "2025-01-01T00:00:00Z", "_count": {"website": 1, "teamUser": 2}}]}`,
+		"/api/teams/" + fixtureTeamID + "/users": `{"data": [{"userId": "user-1", "role": "team-owner",` +
+			`"user": {"id": "user-1", "username": "alice"}}]}`,
+		"/api/teams/" + fixtureTeamID + "/websites": `{"data": [` + website + `]}`,
+		"/api/admin/users": `{"data": [{"id": "user-1", "username": "admin", "role": "admin",` +
+			`"createdAt": "2025-01-01T00:00:00Z"}]}`,
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := fixtures[r.Method+" "+r.URL.Path]
+		if !ok {
+			body, ok = fixtures[r.URL.Path]
+		}
+		if !ok {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+// outputFixtureArguments holds arguments that make each tool succeed against
+// outputFixtureServer.
+func outputFixtureArguments() map[string]map[string]any {
+	site := map[string]any{"website_id": fixtureWebsiteID}
+	ranged := func(extra map[string]any) map[string]any {
+		args := map[string]any{"website_id": fixtureWebsiteID, "start_date": "2025-01-01", "end_date": "2025-01-31"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	return map[string]map[string]any{
+		"get_websites":         {},
+		"get_stats":            ranged(nil),
+		"get_pageviews":        ranged(nil),
+		"get_metrics":          ranged(map[string]any{"metric_type": "path"}),
+		"get_active":           site,
+		"get_events":           ranged(map[string]any{"event_name": "signup", "property_name": "plan"}),
+		"get_sessions":         ranged(nil),
+		"get_session_activity": ranged(map[string]any{"session_id": fixtureSessionID}),
+		"get_funnel": ranged(map[string]any{"steps": []map[string]string{
+			{"type": "path", "value": "/pricing"}, {"type": "path", "value": "/signup"},
+		}}),
+		"get_retention":   ranged(map[string]any{"unit": "week"}),
+		"get_journey":     ranged(nil),
+		"get_utm":         ranged(nil),
+		"get_attribution": ranged(map[string]any{"goal_type": "path", "goal": "/signup"}),
+		"get_revenue":     ranged(nil),
+		"get_goals": ranged(map[string]any{"goals": []map[string]any{
+			{"type": "path", "value": "/signup", "target": 50},
+		}}),
+		"get_realtime":           site,
+		"create_website":         {"name": "Blog", "domain": "blog.example.com"},
+		"update_website":         {"website_id": fixtureWebsiteID, "name": "Blog"},
+		"reset_website_data":     {"website_id": fixtureWebsiteID, "confirm": fixtureWebsiteID},
+		"delete_website":         {"website_id": fixtureWebsiteID, "confirm": fixtureWebsiteID},
+		"enable_share_link":      site,
+		"rotate_share_link":      site,
+		"disable_share_link":     site,
+		"get_teams":              {},
+		"get_team_members":       {"team_id": fixtureTeamID},
+		"get_team_websites":      {"team_id": fixtureTeamID},
+		"get_users":              {},
+		"list_segments":          site,
+		"get_segment":            {"website_id": fixtureWebsiteID, "segment_id": fixtureSegmentID},
+		"get_event_properties":   ranged(map[string]any{"event_name": "signup", "include_series": true}),
+		"get_session_properties": ranged(nil),
+		"lookup_distinct_id":     ranged(map[string]any{"distinct_id": "user-42"}),
+		"get_traffic_heatmap":    ranged(nil),
+		"get_portfolio_stats": {
+			"website_ids": []string{fixtureWebsiteID}, "start_date": "2025-01-01", "end_date": "2025-01-31",
+		},
+		"batch_query": {
+			"defaults": ranged(nil),
+			"queries":  []map[string]any{{"key": "overview", "type": "stats"}},
+		},
+	}
+}
+
+func TestToolOutput_MatchesOutputSchema(t *testing.T) {
+	umami := outputFixtureServer(t)
+	defer umami.Close()
+
+	server := NewMCPServer(&UmamiClient{baseURL: umami.URL, apiKey: "key", httpClient: &http.Client{}})
+	server.allowWrites = true
+	resp := server.HandleRequest(Request{
+		JSONRPC: "2.0", ID: 1, Method: "initialize", Params: json.RawMessage(`{"protocolVersion":"2025-06-18"}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected initialize error: %v", resp.Error)
+	}
+
+	resp = server.HandleRequest(Request{JSONRPC: "2.0", ID: 2, Method: "tools/list"})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected tools/list error: %v", resp.Error)
+	}
+	tools := resp.Result.(map[string]any)["tools"].([]map[string]any)
+
+	arguments := outputFixtureArguments()
+	for _, tool := range tools {
+		name := tool["name"].(string)
+		t.Run(name, func(t *testing.T) {
+			args, ok := arguments[name]
+			if !ok {
+				t.Fatal("No fixture arguments for tool")
+			}
+			checkToolOutput(t, server, tool, args)
+		})
+	}
+}
+
+// checkToolOutput calls tool with args and validates its structured content
+// against the tool's outputSchema.
+func checkToolOutput(t *testing.T, server *MCPServer, tool map[string]any, args any) {
+	t.Helper()
+
+	schema, ok := tool["outputSchema"].(map[string]any)
+	if !ok {
+		t.Fatal("Missing outputSchema")
+	}
+	if schema["type"] != "object" {
+		t.Errorf("outputSchema must describe an object, got %v", schema["type"])
+	}
+
+	params, _ := json.Marshal(map[string]any{"name": tool["name"], "arguments": args})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 3, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	result := resp.Result.(map[string]any)
+	if _, ok := result["content"].([]map[string]string); !ok {
+		t.Error("Missing text content")
+	}
+	structured, ok := result["structuredContent"]
+	if !ok {
+		t.Fatal("Missing structuredContent")
+	}
+
+	data, _ := json.Marshal(structured)
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("structuredContent is not JSON: %v", err)
+	}
+	if err := validateAgainstSchema(schema, value, "$"); err != nil {
+		t.Errorf("Output does not match schema: %v\n%s", err, data)
+	}
+}
+
+func TestToolOutput_OmittedBeforeStructuredOutput(t *testing.T) {
+	umami := outputFixtureServer(t)
+	defer umami.Close()
+
+	server := NewMCPServer(&UmamiClient{baseURL: umami.URL, apiKey: "key", httpClient: &http.Client{}})
+	resp := server.HandleRequest(Request{
+		JSONRPC: "2.0", ID: 1, Method: "initialize", Params: json.RawMessage(`{"protocolVersion":"2025-03-26"}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected initialize error: %v", resp.Error)
+	}
+
+	resp = server.HandleRequest(Request{JSONRPC: "2.0", ID: 2, Method: "tools/list"})
+	for _, tool := range resp.Result.(map[string]any)["tools"].([]map[string]any) {
+		if _, ok := tool["outputSchema"]; ok {
+			t.Errorf("Expected no outputSchema for %v on 2025-03-26", tool["name"])
+		}
+	}
+
+	resp = server.HandleRequest(Request{
+		JSONRPC: "2.0", ID: 3, Method: "tools/call", Params: json.RawMessage(`{"name":"get_websites","arguments":{}}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+	if _, ok := resp.Result.(map[string]any)["structuredContent"]; ok {
+		t.Error("Expected no structuredContent on 2025-03-26")
+	}
+}
+
+func TestListContent_NilListIsEmpty(t *testing.T) {
+	var websites []Website
+	result := listContent("websites", websites)
+
+	if text := result["content"].([]map[string]string)[0]["text"]; text != "[]" {
+		t.Errorf("Expected empty JSON list, got %s", text)
+	}
+	data, _ := json.Marshal(result["structuredContent"])
+	if string(data) != `{"websites":[]}` {
+		t.Errorf("Expected empty websites list, got %s", data)
+	}
+}
+
+// validateAgainstSchema checks value against the subset of JSON Schema used by
+// the output schemas in tools.json: type, properties, required, items,
+// additionalProperties and anyOf.
+func validateAgainstSchema(schema map[string]any, value any, path string) error {
+	if options, ok := schema["anyOf"].([]any); ok {
+		if err := validateAnyOf(options, value, path); err != nil {
+			return err
+		}
+	}
+	if typ, ok := schema["type"]; ok {
+		if err := validateType(typ, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		return validateObject(schema, value, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, v := range value {
+				if err := validateAgainstSchema(items, v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateAnyOf(options []any, value any, path string) error {
+	var errs []error
+	for _, option := range options {
+		err := validateAgainstSchema(option.(map[string]any), value, path)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("%s matches no anyOf option: %v", path, errs)
+}
+
+func validateType(typ, value any, path string) error {
+	var allowed []string
+	switch typ := typ.(type) {
+	case string:
+		allowed = []string{typ}
+	case []any:
+		for _, t := range typ {
+			allowed = append(allowed, t.(string))
+		}
+	}
+
+	actual := jsonType(value)
+	if slices.Contains(allowed, actual) || (actual == "integer" && slices.Contains(allowed, "number")) {
+		return nil
+	}
+	return fmt.Errorf("%s: expected %v, got %s", path, allowed, actual)
+}
+
+func validateObject(schema, value map[string]any, path string) error {
+	required, _ := schema["required"].([]any)
+	for _, key := range required {
+		if _, ok := value[key.(string)]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, key)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for key, v := range value {
+		propertySchema, ok := properties[key].(map[string]any)
+		if !ok {
+			propertySchema, ok = schema["additionalProperties"].(map[string]any)
+		}
+		if !ok {
+			continue
+		}
+		if err := validateAgainstSchema(propertySchema, v, path+"."+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cohortDate trims an Umami timestamp down to its calendar date.
+func cohortDate(date string) string {
+	if len(date) >= 10 {
+		return date[:10]
+	}
+	return date
+}
+
+// weeklyRetention regroups Umami's daily retention rows into weekly cohorts
+// (starting Monday). Umami reports returning visitors per day, not per
+// visitor, so week N of a cohort day is the peak daily return count within
+// days 7N to 7N+6: a lower bound on the visitors who came back that week
+// that never counts anyone twice. Peaks are then summed over the cohort days
+// of the week.
+func weeklyRetention(rows []RetentionRow) []RetentionRow {
+	type cohortKey struct {
+		week   string
+		offset int
+	}
+	type dayKey struct {
+		date   string
+		offset int
+	}
+
+	cohortSizes := make(map[string]map[string]int)
+	peaks := make(map[dayKey]int)
+	weekOf := make(map[string]string)
+
+	for _, r := range rows {
+		date := cohortDate(r.Date)
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+		if _, ok := cohortSizes[week]; !ok {
+			cohortSizes[week] = make(map[string]int)
+		}
+		cohortSizes[week][date] = r.Visitors
+		weekOf[date] = week
+
+		key := dayKey{date: date, offset: r.Day / 7}
+		if peak, ok := peaks[key]; !ok || r.ReturnVisitors > peak {
+			peaks[key] = r.ReturnVisitors
+		}
+	}
+
+	returning := make(map[cohortKey]int)
+	var keys []cohortKey
+	for day, peak := range peaks {
+		key := cohortKey{week: weekOf[day.date], offset: day.offset}
+		if _, ok := returning[key]; !ok {
+			keys = append(keys, key)
+		}
+		returning[key] += peak
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].week != keys[j].week {
+			return keys[i].week < keys[j].week
+		}
+		return keys[i].offset < keys[j].offset
+	})
+
+	weekly := make([]RetentionRow, 0, len(keys))
+	for _, key := range keys {
+		size := 0
+		for _, v := range cohortSizes[key.week] {
+			size += v
+		}
+		weekly = append(weekly, RetentionRow{
+			Date:           key.week,
+			Day:            key.offset,
+			Visitors:       size,
+			ReturnVisitors: returning[key],
+			Percentage:     percentage(returning[key], size),
+		})
+	}
+	return weekly
+}
+
+// retentionTable renders retention rows as a compact cohort matrix with one
+// line per cohort and one column per offset.
+func retentionTable(rows []RetentionRow, unit string) string {
+	prefix := "d"
+	if unit == "week" {
+		prefix = "w"
+	}
+
+	var cohorts []string
+	sizes := make(map[string]int)
+	cells := make(map[string]map[int]float64)
+	maxOffset := 0
+	for _, r := range rows {
+		date := cohortDate(r.Date)
+		if _, ok := cells[date]; !ok {
+			cohorts = append(cohorts, date)
+			cells[date] = make(map[int]float64)
+		}
+		sizes[date] = r.Visitors
+		cells[date][r.Day] = r.Percentage
+		if r.Day > maxOffset {
+			maxOffset = r.Day
+		}
+	}
+	sort.Strings(cohorts)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-10s %8s", "cohort", "visitors")
+	for i := 0; i <= maxOffset; i++ {
+		fmt.Fprintf(&b, " %6s", fmt.Sprintf("%s%d", prefix, i))
+	}
+	b.WriteString("\n")
+	for _, date := range cohorts {
+		fmt.Fprintf(&b, "%-10s %8d", date, sizes[date])
+		for i := 0; i <= maxOffset; i++ {
+			if pct, ok := cells[date][i]; ok {
+				fmt.Fprintf(&b, " %5.1f%%", pct)
+			} else {
+				fmt.Fprintf(&b, " %6s", "-")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
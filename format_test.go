@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWeeklyRetention(t *testing.T) {
+	rows := []RetentionRow{
+		{Date: "2025-01-06T00:00:00Z", Day: 0, Visitors: 100, ReturnVisitors: 100},
+		{Date: "2025-01-06T00:00:00Z", Day: 3, Visitors: 100, ReturnVisitors: 30},
+		{Date: "2025-01-06T00:00:00Z", Day: 7, Visitors: 100, ReturnVisitors: 20},
+		{Date: "2025-01-06T00:00:00Z", Day: 9, Visitors: 100, ReturnVisitors: 35},
+		{Date: "2025-01-08T00:00:00Z", Day: 0, Visitors: 50, ReturnVisitors: 50},
+		{Date: "2025-01-08T00:00:00Z", Day: 7, Visitors: 50, ReturnVisitors: 10},
+		{Date: "2025-01-13T00:00:00Z", Day: 0, Visitors: 40, ReturnVisitors: 40},
+	}
+
+	weekly := weeklyRetention(rows)
+
+	if len(weekly) != 3 {
+		t.Fatalf("Expected 3 weekly rows, got %d: %+v", len(weekly), weekly)
+	}
+
+	if weekly[0].Date != "2025-01-06" || weekly[0].Day != 0 || weekly[0].Visitors != 150 {
+		t.Errorf("Unexpected first cohort: %+v", weekly[0])
+	}
+
+	// Week 1 takes each cohort day's peak within days 7-13: 35 (day 9) + 10.
+	if weekly[1].Day != 1 || weekly[1].ReturnVisitors != 45 || weekly[1].Percentage != 30 {
+		t.Errorf("Expected week 1 with 45 returning (30%%), got %+v", weekly[1])
+	}
+
+	if weekly[2].Date != "2025-01-13" || weekly[2].Visitors != 40 {
+		t.Errorf("Unexpected second cohort: %+v", weekly[2])
+	}
+}
+
+func TestRetentionTable(t *testing.T) {
+	rows := []RetentionRow{
+		{Date: "2025-01-06T00:00:00Z", Day: 0, Visitors: 80, Percentage: 100},
+		{Date: "2025-01-06T00:00:00Z", Day: 1, Visitors: 80, Percentage: 15},
+		{Date: "2025-01-07T00:00:00Z", Day: 0, Visitors: 60, Percentage: 100},
+	}
+
+	table := retentionTable(rows, "day")
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 cohort lines, got %d:\n%s", len(lines), table)
+	}
+
+	if !strings.Contains(lines[0], "d0") || !strings.Contains(lines[0], "d1") {
+		t.Errorf("Expected day offset columns in header, got %q", lines[0])
+	}
+
+	if !strings.HasPrefix(lines[1], "2025-01-06") || !strings.Contains(lines[1], "15.0%") {
+		t.Errorf("Unexpected first cohort line: %q", lines[1])
+	}
+
+	if !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("Expected missing offset to render as '-', got %q", lines[2])
+	}
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 )
 
 func (s *MCPServer) execGetWebsites(args json.RawMessage) (any, *Error) {
@@ -19,20 +23,80 @@ func (s *MCPServer) execGetWebsites(args json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get websites: %v", err)}
 	}
 
-	data, _ := json.MarshalIndent(websites, "", "  ")
-	content := []map[string]string{{
-		"type": "text",
-		"text": string(data),
-	}}
+	return listContent("websites", websites), nil
+}
+
+func (s *MCPServer) execGetTeams(_ json.RawMessage) (any, *Error) {
+	teams, err := s.client.GetTeams()
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get teams: %v", err)}
+	}
+
+	return listContent("teams", teams), nil
+}
+
+func parseTeamID(args json.RawMessage) (string, *Error) {
+	var params struct {
+		TeamID string `json:"team_id"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateTeamID(params.TeamID); err != nil {
+		return "", &Error{Code: -32602, Message: "Invalid team_id"}
+	}
+
+	return params.TeamID, nil
+}
+
+func (s *MCPServer) execGetTeamMembers(args json.RawMessage) (any, *Error) {
+	teamID, rpcErr := parseTeamID(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	members, err := s.client.GetTeamMembers(teamID)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get team members: %v", err)}
+	}
+
+	return listContent("members", members), nil
+}
+
+func (s *MCPServer) execGetTeamWebsites(args json.RawMessage) (any, *Error) {
+	teamID, rpcErr := parseTeamID(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	websites, err := s.client.GetTeamWebsites(teamID)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get team websites: %v", err)}
+	}
+
+	return listContent("websites", websites), nil
+}
+
+func (s *MCPServer) execGetUsers(_ json.RawMessage) (any, *Error) {
+	users, err := s.client.GetUsers()
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get users (admin access required): %v", err)}
+	}
 
-	return map[string]any{"content": content}, nil
+	return listContent("users", users), nil
 }
 
 func (s *MCPServer) execGetStats(args json.RawMessage) (any, *Error) {
 	var params struct {
-		WebsiteID string `json:"website_id"`
-		StartDate string `json:"start_date"`
-		EndDate   string `json:"end_date"`
+		WebsiteID        string `json:"website_id"`
+		StartDate        string `json:"start_date"`
+		EndDate          string `json:"end_date"`
+		Compare          string `json:"compare"`
+		CompareStartDate string `json:"compare_start_date"`
+		CompareEndDate   string `json:"compare_end_date"`
+		analyticsFilters
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -43,21 +107,180 @@ func (s *MCPServer) execGetStats(args json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
 	}
 
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
 	params.StartDate = normalizeDate(params.StartDate)
 	params.EndDate = normalizeDate(params.EndDate)
 
-	stats, err := s.client.GetStats(params.WebsiteID, params.StartDate, params.EndDate)
+	var result any
+	if params.Compare == "" {
+		stats, err := s.client.GetStats(params.WebsiteID, params.StartDate, params.EndDate, params.Filters)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get stats: %v", err)}
+		}
+		result = stats
+	} else {
+		report, rpcErr := s.compareStats(
+			params.WebsiteID, params.StartDate, params.EndDate, params.Compare,
+			normalizeDate(params.CompareStartDate), normalizeDate(params.CompareEndDate), params.Filters,
+		)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		result = report
+	}
+
+	return jsonContent(result), nil
+}
+
+// compareStats builds a period-over-period report. It uses the comparison
+// Umami returns when available and otherwise fetches the second range itself.
+func (s *MCPServer) compareStats(
+	websiteID, startDate, endDate, mode, compareStart, compareEnd string, filters map[string]string,
+) (*StatsReport, *Error) {
+	var prevStart, prevEnd string
+	switch mode {
+	case comparePreviousPeriod, comparePreviousYear:
+		var err error
+		prevStart, prevEnd, err = comparisonRange(startDate, endDate, mode)
+		if err != nil {
+			return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid compare range: %v", err)}
+		}
+	case compareCustom:
+		if compareStart == "" || compareEnd == "" {
+			return nil, &Error{
+				Code:    -32602,
+				Message: "compare 'custom' requires compare_start_date and compare_end_date",
+			}
+		}
+		prevStart, prevEnd = compareStart, compareEnd
+	default:
+		return nil, &Error{
+			Code:    -32602,
+			Message: "Invalid compare: must be previous_period, previous_year or custom",
+		}
+	}
+
+	stats, err := s.client.GetStatsCompared(websiteID, startDate, endDate, umamiCompareModes[mode], filters)
 	if err != nil {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get stats: %v", err)}
 	}
 
-	data, _ := json.MarshalIndent(stats, "", "  ")
-	content := []map[string]string{{
-		"type": "text",
-		"text": string(data),
-	}}
+	prev := stats.Comparison
+	if prev == nil || mode == compareCustom {
+		prevStats, err := s.client.GetStats(websiteID, prevStart, prevEnd, filters)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get comparison stats: %v", err)}
+		}
+		totals := prevStats.totals()
+		prev = &totals
+	}
+
+	current := newPeriodStats(startDate, endDate, stats.totals())
+	previous := newPeriodStats(prevStart, prevEnd, *prev)
+	report := buildStatsReport(mode, current, previous)
+	return &report, nil
+}
+
+func (s *MCPServer) execGetPortfolioStats(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteIDs []string `json:"website_ids"`
+		StartDate  string   `json:"start_date"`
+		EndDate    string   `json:"end_date"`
+		Metric     string   `json:"metric"`
+		Sort       string   `json:"sort"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	for _, id := range params.WebsiteIDs {
+		if err := validateWebsiteID(id); err != nil {
+			return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid website_id %q", id)}
+		}
+	}
+
+	if err := params.resolveFilters(s.portfolioSegmentType(params.WebsiteIDs)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	metric, order, err := portfolioOptions(params.Metric, params.Sort)
+	if err != nil {
+		return nil, &Error{Code: -32602, Message: err.Error()}
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	// Names come from the website list; an explicit list still works when it can't be fetched
+	all, err := s.client.GetWebsites(false)
+	if err != nil && len(params.WebsiteIDs) == 0 {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get websites: %v", err)}
+	}
+	websites := selectWebsites(all, params.WebsiteIDs)
+
+	sites := fetchPortfolio(websites, portfolioWorkers, func(w Website) (*StatsReport, error) {
+		report, rpcErr := s.compareStats(
+			w.ID, params.StartDate, params.EndDate, comparePreviousPeriod, "", "", params.Filters,
+		)
+		if rpcErr != nil {
+			return nil, errors.New(rpcErr.Message)
+		}
+		return report, nil
+	})
+	report := rankPortfolio(sites, metric, order)
+
+	return jsonContent(report, portfolioTable(report)), nil
+}
+
+func (s *MCPServer) execBatchQuery(args json.RawMessage) (any, *Error) {
+	var params struct {
+		Defaults map[string]json.RawMessage `json:"defaults"`
+		Queries  []BatchQuery               `json:"queries"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateBatchQueries(params.Queries); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid queries: %v", err)}
+	}
+
+	outputs := make([]json.RawMessage, len(params.Queries))
+	failures := make([]string, len(params.Queries))
+	forEachConcurrently(len(params.Queries), batchWorkers, func(i int) {
+		q := params.Queries[i]
+		queryArgs, err := mergeArguments(params.Defaults, q.Arguments)
+		if err != nil {
+			failures[i] = "Invalid arguments"
+			return
+		}
+		result, rpcErr := batchHandlers[q.Type](s, queryArgs)
+		if rpcErr != nil {
+			failures[i] = rpcErr.Message
+			return
+		}
+		outputs[i] = toolOutput(result)
+	})
 
-	return map[string]any{"content": content}, nil
+	batch := BatchResult{Results: make(map[string]json.RawMessage)}
+	for i, q := range params.Queries {
+		if failures[i] != "" {
+			if batch.Errors == nil {
+				batch.Errors = make(map[string]string)
+			}
+			batch.Errors[q.Key] = failures[i]
+			continue
+		}
+		batch.Results[q.Key] = outputs[i]
+	}
+
+	return jsonContent(batch), nil
 }
 
 func (s *MCPServer) execGetPageViews(args json.RawMessage) (any, *Error) {
@@ -66,6 +289,7 @@ func (s *MCPServer) execGetPageViews(args json.RawMessage) (any, *Error) {
 		StartDate string `json:"start_date"`
 		EndDate   string `json:"end_date"`
 		Unit      string `json:"unit"`
+		analyticsFilters
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -76,6 +300,10 @@ func (s *MCPServer) execGetPageViews(args json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
 	}
 
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
 	if params.Unit == "" {
 		params.Unit = "day"
 	}
@@ -83,18 +311,74 @@ func (s *MCPServer) execGetPageViews(args json.RawMessage) (any, *Error) {
 	params.StartDate = normalizeDate(params.StartDate)
 	params.EndDate = normalizeDate(params.EndDate)
 
-	pageviews, err := s.client.GetPageViews(params.WebsiteID, params.StartDate, params.EndDate, params.Unit)
+	pageviews, err := s.client.GetPageViews(
+		params.WebsiteID, params.StartDate, params.EndDate, params.Unit, params.Filters,
+	)
 	if err != nil {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get page views: %v", err)}
 	}
 
-	data, _ := json.MarshalIndent(pageviews, "", "  ")
-	content := []map[string]string{{
-		"type": "text",
-		"text": string(data),
-	}}
+	return listContent("pageviews", pageviews), nil
+}
+
+func (s *MCPServer) execGetTrafficHeatmap(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Timezone  string `json:"timezone"`
+		Metric    string `json:"metric"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Metric == "" {
+		params.Metric = "visitors"
+	}
+	if params.Metric != "visitors" && params.Metric != "pageviews" {
+		return nil, &Error{Code: -32602, Message: "metric must be visitors or pageviews"}
+	}
+
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(params.Timezone)
+	if err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid timezone"}
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	series, err := s.client.GetHourlyTrafficSeries(
+		params.WebsiteID, params.StartDate, params.EndDate, params.Timezone, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get traffic: %v", err)}
+	}
+
+	points := series.Sessions
+	if params.Metric == "pageviews" {
+		points = series.PageViews
+	}
+
+	heatmap, err := buildHeatmap(points, loc, params.Metric)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to build heatmap: %v", err)}
+	}
 
-	return map[string]any{"content": content}, nil
+	return jsonContent(heatmap), nil
 }
 
 func (s *MCPServer) execGetMetrics(args json.RawMessage) (any, *Error) {
@@ -104,6 +388,7 @@ func (s *MCPServer) execGetMetrics(args json.RawMessage) (any, *Error) {
 		EndDate    string `json:"end_date"`
 		MetricType string `json:"metric_type"`
 		Limit      int    `json:"limit"`
+		analyticsFilters
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -114,6 +399,10 @@ func (s *MCPServer) execGetMetrics(args json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
 	}
 
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
 	if params.Limit == 0 {
 		params.Limit = 10
 	}
@@ -122,19 +411,13 @@ func (s *MCPServer) execGetMetrics(args json.RawMessage) (any, *Error) {
 	params.EndDate = normalizeDate(params.EndDate)
 
 	metrics, err := s.client.GetMetrics(
-		params.WebsiteID, params.StartDate, params.EndDate, params.MetricType, params.Limit,
+		params.WebsiteID, params.StartDate, params.EndDate, params.MetricType, params.Limit, params.Filters,
 	)
 	if err != nil {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get metrics: %v", err)}
 	}
 
-	data, _ := json.MarshalIndent(metrics, "", "  ")
-	content := []map[string]string{{
-		"type": "text",
-		"text": string(data),
-	}}
-
-	return map[string]any{"content": content}, nil
+	return listContent("metrics", metrics), nil
 }
 
 func (s *MCPServer) execGetActive(args json.RawMessage) (any, *Error) {
@@ -155,11 +438,1013 @@ func (s *MCPServer) execGetActive(args json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get active visitors: %v", err)}
 	}
 
-	data, _ := json.MarshalIndent(active, "", "  ")
-	content := []map[string]string{{
-		"type": "text",
-		"text": string(data),
-	}}
+	return listContent("active", active), nil
+}
+
+// realtimeWindow matches the window Umami's own realtime dashboard shows.
+const realtimeWindow = 30 * time.Minute
+
+func (s *MCPServer) execGetRealtime(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		Limit     int    `json:"limit"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+
+	realtime, err := s.client.GetRealtime(params.WebsiteID, time.Now().Add(-realtimeWindow))
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get realtime data: %v", err)}
+	}
+
+	active, err := s.client.GetActive(params.WebsiteID)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get active visitors: %v", err)}
+	}
+	if len(active) > 0 {
+		realtime.ActiveVisitors = active[0].Y
+	}
+
+	if len(realtime.Events) > params.Limit {
+		realtime.Events = realtime.Events[:params.Limit]
+	}
+
+	return jsonContent(realtime), nil
+}
+
+func (s *MCPServer) execGetEvents(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID    string `json:"website_id"`
+		StartDate    string `json:"start_date"`
+		EndDate      string `json:"end_date"`
+		Unit         string `json:"unit"`
+		EventName    string `json:"event_name"`
+		PropertyName string `json:"property_name"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.PropertyName != "" && params.EventName == "" {
+		return nil, &Error{Code: -32602, Message: "property_name requires event_name"}
+	}
+
+	if params.Unit == "" {
+		params.Unit = "day"
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	events, err := s.client.GetEvents(
+		params.WebsiteID, params.StartDate, params.EndDate, params.EventName, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get events: %v", err)}
+	}
+
+	series, err := s.client.GetEventSeries(
+		params.WebsiteID, params.StartDate, params.EndDate, params.Unit, params.EventName, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get event series: %v", err)}
+	}
+
+	result := map[string]any{
+		"events": events,
+		"series": series,
+	}
+
+	if params.PropertyName != "" {
+		values, err := s.client.GetEventValues(
+			params.WebsiteID, params.StartDate, params.EndDate, params.EventName, params.PropertyName, params.Filters,
+		)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get event values: %v", err)}
+		}
+		result["values"] = values
+	}
+
+	return jsonContent(result), nil
+}
+
+func (s *MCPServer) execGetEventProperties(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID     string `json:"website_id"`
+		StartDate     string `json:"start_date"`
+		EndDate       string `json:"end_date"`
+		EventName     string `json:"event_name"`
+		PropertyName  string `json:"property_name"`
+		Limit         int    `json:"limit"`
+		IncludeSeries bool   `json:"include_series"`
+		Unit          string `json:"unit"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.EventName == "" {
+		return nil, &Error{Code: -32602, Message: "event_name is required"}
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	if params.Unit == "" {
+		params.Unit = "day"
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	events, err := s.client.GetEvents(
+		params.WebsiteID, params.StartDate, params.EndDate, params.EventName, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get event properties: %v", err)}
+	}
+
+	properties := eventPropertyKeys(events, params.EventName)
+	if params.PropertyName != "" {
+		properties = slices.DeleteFunc(properties, func(p EventProperty) bool {
+			return p.Name != params.PropertyName
+		})
+		if len(properties) == 0 {
+			properties = []EventProperty{{Name: params.PropertyName, DataType: "unknown"}}
+		}
+	}
+
+	for i := range properties {
+		p := &properties[i]
+		values, err := s.client.GetEventValues(
+			params.WebsiteID, params.StartDate, params.EndDate, params.EventName, p.Name, params.Filters,
+		)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get values of %s: %v", p.Name, err)}
+		}
+		p.Values = eventPropertyValues(values, params.Limit)
+
+		if !params.IncludeSeries {
+			continue
+		}
+		err = s.fillValueSeries(
+			params.WebsiteID, params.StartDate, params.EndDate, params.Unit, params.EventName, params.Filters, p,
+		)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get series of %s: %v", p.Name, err)}
+		}
+	}
+
+	result := map[string]any{
+		"event":      params.EventName,
+		"properties": properties,
+	}
+
+	return jsonContent(result), nil
+}
+
+// fillValueSeries fetches a series for every value of p. When Umami ignores
+// the value filter the series are dropped and p gets a note instead.
+func (s *MCPServer) fillValueSeries(
+	websiteID, startDate, endDate, unit, eventName string, filters map[string]string, p *EventProperty,
+) error {
+	for j := range p.Values {
+		v := &p.Values[j]
+		series, err := s.client.GetEventValueSeries(
+			websiteID, startDate, endDate, unit, eventName, p.Name, fmt.Sprint(v.Value), filters,
+		)
+		if err != nil {
+			return err
+		}
+		v.Series = series
+	}
+
+	if !valueSeriesFiltered(p.Values) {
+		for j := range p.Values {
+			p.Values[j].Series = nil
+		}
+		p.Note = seriesUnfilteredNote
+	}
+	return nil
+}
+
+func (s *MCPServer) execGetSessions(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Page      int    `json:"page"`
+		PageSize  int    `json:"page_size"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 20
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	sessions, err := s.client.GetSessions(
+		params.WebsiteID, params.StartDate, params.EndDate, params.Page, params.PageSize, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get sessions: %v", err)}
+	}
+
+	return jsonContent(sessions), nil
+}
+
+func (s *MCPServer) execGetSessionActivity(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		SessionID string `json:"session_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := validateSessionID(params.SessionID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid session_id"}
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	activity, err := s.client.GetSessionActivity(
+		params.WebsiteID, params.SessionID, params.StartDate, params.EndDate,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get session activity: %v", err)}
+	}
+
+	result := map[string]any{"activity": activity}
+
+	// Session properties are only available on Umami instances with session data enabled
+	if properties, err := s.client.GetSessionProperties(params.WebsiteID, params.SessionID); err == nil {
+		result["properties"] = properties
+	}
+
+	return jsonContent(result), nil
+}
+
+func (s *MCPServer) execGetSessionProperties(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID    string `json:"website_id"`
+		StartDate    string `json:"start_date"`
+		EndDate      string `json:"end_date"`
+		PropertyName string `json:"property_name"`
+		Limit        int    `json:"limit"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	keys, err := s.client.GetSessionDataProperties(params.WebsiteID, params.StartDate, params.EndDate, params.Filters)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get session properties: %v", err)}
+	}
+
+	properties := make([]SessionDataProperty, 0, len(keys))
+	for _, k := range keys {
+		if params.PropertyName == "" || k.PropertyName == params.PropertyName {
+			properties = append(properties, SessionDataProperty{Name: k.PropertyName, Total: k.Total})
+		}
+	}
+	if params.PropertyName != "" && len(properties) == 0 {
+		properties = append(properties, SessionDataProperty{Name: params.PropertyName})
+	}
+
+	for i := range properties {
+		p := &properties[i]
+		values, err := s.client.GetSessionDataValues(
+			params.WebsiteID, params.StartDate, params.EndDate, p.Name, params.Filters,
+		)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get values of %s: %v", p.Name, err)}
+		}
+		p.Values = eventPropertyValues(values, params.Limit)
+	}
+
+	return listContent("properties", properties), nil
+}
+
+func (s *MCPServer) execLookupDistinctID(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID   string `json:"website_id"`
+		DistinctID  string `json:"distinct_id"`
+		StartDate   string `json:"start_date"`
+		EndDate     string `json:"end_date"`
+		MaxSessions int    `json:"max_sessions"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if params.DistinctID == "" {
+		return nil, &Error{Code: -32602, Message: "distinct_id is required"}
+	}
+
+	if params.MaxSessions <= 0 {
+		params.MaxSessions = 5
+	}
+
+	params.StartDate = normalizeDate(params.StartDate)
+	params.EndDate = normalizeDate(params.EndDate)
+
+	sessions, err := s.client.GetSessionsByDistinctID(
+		params.WebsiteID, params.DistinctID, params.StartDate, params.EndDate, params.MaxSessions,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get sessions: %v", err)}
+	}
+
+	userSessions := make([]UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		activity, err := s.client.GetSessionActivity(params.WebsiteID, session.ID, params.StartDate, params.EndDate)
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get session activity: %v", err)}
+		}
+
+		userSession := UserSession{Session: session, Activity: activity}
+		if properties, err := s.client.GetSessionProperties(params.WebsiteID, session.ID); err == nil {
+			userSession.Properties = properties
+		}
+		userSessions = append(userSessions, userSession)
+	}
+
+	result := map[string]any{
+		"distinctId": params.DistinctID,
+		"sessions":   userSessions,
+	}
+
+	return jsonContent(result), nil
+}
+
+func (s *MCPServer) execGetFunnel(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string       `json:"website_id"`
+		StartDate string       `json:"start_date"`
+		EndDate   string       `json:"end_date"`
+		Steps     []FunnelStep `json:"steps"`
+		Window    int          `json:"window"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if len(params.Steps) < 2 {
+		return nil, &Error{Code: -32602, Message: "A funnel needs at least 2 steps"}
+	}
+	for i, step := range params.Steps {
+		if !isStepType(step.Type) || step.Value == "" {
+			return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid step %d: type must be path or event", i+1)}
+		}
+	}
+
+	if params.Window <= 0 {
+		params.Window = 60
+	}
+
+	funnel, err := s.client.GetFunnel(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate),
+		params.Steps, params.Window, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get funnel: %v", err)}
+	}
+
+	return listContent("steps", funnel), nil
+}
+
+func (s *MCPServer) execGetRetention(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Unit      string `json:"unit"`
+		Timezone  string `json:"timezone"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Unit == "" {
+		params.Unit = "day"
+	}
+	if params.Unit != "day" && params.Unit != "week" {
+		return nil, &Error{Code: -32602, Message: "Invalid unit: must be day or week"}
+	}
+
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(params.Timezone); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid timezone"}
+	}
+
+	rows, err := s.client.GetRetention(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate),
+		params.Timezone, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get retention: %v", err)}
+	}
+
+	if params.Unit == "week" {
+		rows = weeklyRetention(rows)
+	}
+
+	result := map[string]any{
+		"unit":    params.Unit,
+		"cohorts": rows,
+	}
+
+	return jsonContent(result, retentionTable(rows, params.Unit)), nil
+}
+
+func (s *MCPServer) execGetJourney(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Steps     int    `json:"steps"`
+		StartStep string `json:"start_step"`
+		EndStep   string `json:"end_step"`
+		Limit     int    `json:"limit"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Steps == 0 {
+		params.Steps = 5
+	}
+	if params.Steps < 3 || params.Steps > 7 {
+		return nil, &Error{Code: -32602, Message: "Invalid steps: must be between 3 and 7"}
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+
+	paths, err := s.client.GetJourney(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate),
+		params.Steps, params.StartStep, params.EndStep, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get journey: %v", err)}
+	}
+
+	if len(paths) > params.Limit {
+		paths = paths[:params.Limit]
+	}
+
+	return listContent("paths", paths), nil
+}
+
+func (s *MCPServer) execGetUTM(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	utm, err := s.client.GetUTM(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate), params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get UTM breakdown: %v", err)}
+	}
+
+	return jsonContent(utm), nil
+}
+
+var attributionModels = map[string]string{
+	"first_click": "firstClick",
+	"last_click":  "lastClick",
+}
+
+func (s *MCPServer) execGetAttribution(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Model     string `json:"model"`
+		GoalType  string `json:"goal_type"`
+		Goal      string `json:"goal"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Model == "" {
+		params.Model = "first_click"
+	}
+	model, ok := attributionModels[params.Model]
+	if !ok {
+		return nil, &Error{Code: -32602, Message: "Invalid model: must be first_click or last_click"}
+	}
+
+	if !isStepType(params.GoalType) || params.Goal == "" {
+		return nil, &Error{Code: -32602, Message: "Invalid goal: goal_type must be path or event and goal is required"}
+	}
+
+	attribution, err := s.client.GetAttribution(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate),
+		model, params.GoalType, params.Goal, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get attribution: %v", err)}
+	}
+
+	return jsonContent(attribution), nil
+}
+
+func (s *MCPServer) execGetRevenue(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Unit      string `json:"unit"`
+		Timezone  string `json:"timezone"`
+		Currency  string `json:"currency"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if params.Currency == "" {
+		params.Currency = "USD"
+	}
+	params.Currency = strings.ToUpper(params.Currency)
+	if err := validateCurrency(params.Currency); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid currency: use a 3-letter ISO 4217 code"}
+	}
+
+	if params.Unit == "" {
+		params.Unit = "day"
+	}
+
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(params.Timezone); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid timezone"}
+	}
+
+	revenue, err := s.client.GetRevenue(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate),
+		params.Unit, params.Timezone, params.Currency, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get revenue: %v", err)}
+	}
+
+	return jsonContent(revenue), nil
+}
+
+func (s *MCPServer) execGetGoals(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Goals     []Goal `json:"goals"`
+		analyticsFilters
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if err := params.resolveFilters(s.segmentType(params.WebsiteID)); err != nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid filters: %v", err)}
+	}
+
+	if len(params.Goals) == 0 {
+		return nil, &Error{Code: -32602, Message: "At least one goal is required"}
+	}
+	for i, goal := range params.Goals {
+		if !isStepType(goal.Type) || goal.Value == "" || goal.Target <= 0 {
+			return nil, &Error{Code: -32602, Message: fmt.Sprintf(
+				"Invalid goal %d: type must be path or event, value is required and target must be positive", i+1,
+			)}
+		}
+	}
+
+	goals, err := s.client.GetGoals(
+		params.WebsiteID, reportDate(params.StartDate), reportDate(params.EndDate), params.Goals, params.Filters,
+	)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get goals: %v", err)}
+	}
+
+	return listContent("goals", goals), nil
+}
+
+func (s *MCPServer) execListSegments(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		Type      string `json:"type"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if params.Type != "" && params.Type != "segment" && params.Type != "cohort" {
+		return nil, &Error{Code: -32602, Message: "type must be segment or cohort"}
+	}
+
+	segments, err := s.client.GetSegments(params.WebsiteID, params.Type)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to list segments: %v", err)}
+	}
+
+	return listContent("segments", segments), nil
+}
+
+// segmentType looks up whether a saved segment ID of websiteID is a
+// "segment" or a "cohort".
+func (s *MCPServer) segmentType(websiteID string) func(string) (string, error) {
+	return func(segmentID string) (string, error) {
+		segment, err := s.client.GetSegment(websiteID, segmentID)
+		if err != nil {
+			return "", err
+		}
+		return segment.Type, nil
+	}
+}
+
+// portfolioSegmentType resolves segment_id for a portfolio. Saved segments
+// belong to a single website, so segment_id can't span several of them.
+func (s *MCPServer) portfolioSegmentType(websiteIDs []string) func(string) (string, error) {
+	if len(websiteIDs) == 1 {
+		return s.segmentType(websiteIDs[0])
+	}
+	return func(string) (string, error) {
+		return "", errors.New("requires exactly one website in website_ids")
+	}
+}
+
+func (s *MCPServer) execGetSegment(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		SegmentID string `json:"segment_id"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if !isHexID(params.SegmentID) {
+		return nil, &Error{Code: -32602, Message: "Invalid segment_id"}
+	}
+
+	segment, err := s.client.GetSegment(params.WebsiteID, params.SegmentID)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get segment: %v", err)}
+	}
+
+	return jsonContent(segment), nil
+}
+
+func (s *MCPServer) execCreateWebsite(args json.RawMessage) (any, *Error) {
+	var params struct {
+		Name   string `json:"name"`
+		Domain string `json:"domain"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if params.Name == "" || params.Domain == "" {
+		return nil, &Error{Code: -32602, Message: "name and domain are required"}
+	}
+
+	website, err := s.client.CreateWebsite(params.Name, params.Domain)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to create website: %v", err)}
+	}
+
+	return jsonContent(website), nil
+}
+
+func (s *MCPServer) execUpdateWebsite(args json.RawMessage) (any, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+		Name      string `json:"name"`
+		Domain    string `json:"domain"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if params.Name == "" && params.Domain == "" {
+		return nil, &Error{Code: -32602, Message: "Provide name or domain to update"}
+	}
+
+	website, err := s.client.UpdateWebsite(params.WebsiteID, params.Name, params.Domain)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to update website: %v", err)}
+	}
+
+	return jsonContent(website), nil
+}
+
+// destructiveParams are the arguments of tools that irreversibly remove data.
+// Confirm must repeat the website ID so a wrong ID cannot slip through.
+type destructiveParams struct {
+	WebsiteID string `json:"website_id"`
+	Confirm   string `json:"confirm"`
+}
+
+func parseDestructiveParams(args json.RawMessage) (*destructiveParams, *Error) {
+	var params destructiveParams
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	if params.Confirm != params.WebsiteID {
+		return nil, &Error{Code: -32602, Message: "confirm must exactly match website_id"}
+	}
+
+	return &params, nil
+}
+
+func (s *MCPServer) execResetWebsiteData(args json.RawMessage) (any, *Error) {
+	params, rpcErr := parseDestructiveParams(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if err := s.client.ResetWebsite(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to reset website data: %v", err)}
+	}
+
+	return textContent(
+		fmt.Sprintf("All analytics data for website %s has been deleted.", params.WebsiteID),
+		map[string]any{"websiteId": params.WebsiteID, "reset": true},
+	), nil
+}
+
+func (s *MCPServer) execDeleteWebsite(args json.RawMessage) (any, *Error) {
+	params, rpcErr := parseDestructiveParams(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if err := s.client.DeleteWebsite(params.WebsiteID); err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to delete website: %v", err)}
+	}
+
+	return textContent(
+		fmt.Sprintf("Website %s has been deleted.", params.WebsiteID),
+		map[string]any{"websiteId": params.WebsiteID, "deleted": true},
+	), nil
+}
+
+// ShareLink describes the public dashboard link of a website.
+type ShareLink struct {
+	WebsiteID string `json:"websiteId"`
+	Enabled   bool   `json:"enabled"`
+	ShareID   string `json:"shareId,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+func (s *MCPServer) shareLinkResult(website *Website) (any, *Error) {
+	link := ShareLink{WebsiteID: website.ID, Enabled: website.ShareID != ""}
+	if link.Enabled {
+		link.ShareID = website.ShareID
+		link.URL = s.client.ShareURL(website.ShareID)
+	}
+
+	return jsonContent(link), nil
+}
+
+func parseShareLinkParams(args json.RawMessage) (string, *Error) {
+	var params struct {
+		WebsiteID string `json:"website_id"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return "", &Error{Code: -32602, Message: "Invalid arguments"}
+	}
+
+	if err := validateWebsiteID(params.WebsiteID); err != nil {
+		return "", &Error{Code: -32602, Message: "Invalid website_id"}
+	}
+
+	return params.WebsiteID, nil
+}
+
+func (s *MCPServer) execEnableShareLink(args json.RawMessage) (any, *Error) {
+	websiteID, rpcErr := parseShareLinkParams(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	website, err := s.client.GetWebsite(websiteID)
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to get website: %v", err)}
+	}
+
+	// Keep an existing share ID so links already handed out stay valid.
+	if website.ShareID == "" {
+		website, err = s.client.SetShareID(websiteID, generateShareID())
+		if err != nil {
+			return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to enable share link: %v", err)}
+		}
+	}
+
+	return s.shareLinkResult(website)
+}
+
+func (s *MCPServer) execRotateShareLink(args json.RawMessage) (any, *Error) {
+	websiteID, rpcErr := parseShareLinkParams(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	website, err := s.client.SetShareID(websiteID, generateShareID())
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to rotate share link: %v", err)}
+	}
+
+	return s.shareLinkResult(website)
+}
+
+func (s *MCPServer) execDisableShareLink(args json.RawMessage) (any, *Error) {
+	websiteID, rpcErr := parseShareLinkParams(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	website, err := s.client.SetShareID(websiteID, "")
+	if err != nil {
+		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to disable share link: %v", err)}
+	}
 
-	return map[string]any{"content": content}, nil
+	return s.shareLinkResult(website)
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// heatmapDays orders the heatmap rows Monday first.
+var heatmapDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+type HeatmapSlot struct {
+	Day   string `json:"day"`
+	Hour  int    `json:"hour"`
+	Value int    `json:"value"`
+}
+
+type Heatmap struct {
+	Timezone string      `json:"timezone"`
+	Metric   string      `json:"metric"`
+	Days     []string    `json:"days"`
+	Matrix   [7][24]int  `json:"matrix"`
+	Total    int         `json:"total"`
+	Peak     HeatmapSlot `json:"peak"`
+	Trough   HeatmapSlot `json:"trough"`
+}
+
+// parseBucketTime reads an Umami series label. Labels with an offset are
+// converted into loc; bare labels are already wall-clock time in loc.
+func parseBucketTime(label string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, label); err == nil {
+		return t.In(loc), true
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, label, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// errDailySeries reports a series that Umami bucketed by day although hours
+// were requested, which would pile all traffic into hour 0.
+var errDailySeries = errors.New("traffic series is bucketed by day, not by hour")
+
+// buildHeatmap folds an hourly series into a day-of-week by hour matrix.
+// Ties for peak and trough go to the earliest slot in the week.
+func buildHeatmap(points []PageView, loc *time.Location, metric string) (Heatmap, error) {
+	heatmap := Heatmap{Timezone: loc.String(), Metric: metric, Days: heatmapDays}
+
+	times := make([]time.Time, 0, len(points))
+	values := make([]int, 0, len(points))
+	for _, p := range points {
+		t, ok := parseBucketTime(p.time(), loc)
+		if !ok {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, p.Y)
+	}
+	if isDailySeries(times) {
+		return Heatmap{}, errDailySeries
+	}
+
+	for i, t := range times {
+		day := (int(t.Weekday()) + 6) % 7
+		heatmap.Matrix[day][t.Hour()] += values[i]
+		heatmap.Total += values[i]
+	}
+
+	heatmap.Peak = HeatmapSlot{Day: heatmapDays[0], Value: heatmap.Matrix[0][0]}
+	heatmap.Trough = heatmap.Peak
+	for day := range heatmap.Matrix {
+		for hour, value := range heatmap.Matrix[day] {
+			if value > heatmap.Peak.Value {
+				heatmap.Peak = HeatmapSlot{Day: heatmapDays[day], Hour: hour, Value: value}
+			}
+			if value < heatmap.Trough.Value {
+				heatmap.Trough = HeatmapSlot{Day: heatmapDays[day], Hour: hour, Value: value}
+			}
+		}
+	}
+
+	return heatmap, nil
+}
+
+// isDailySeries reports whether every bucket starts at midnight across more
+// than one day, which is how a day-granular series looks.
+func isDailySeries(times []time.Time) bool {
+	days := make(map[string]bool)
+	for _, t := range times {
+		if t.Hour() != 0 || t.Minute() != 0 {
+			return false
+		}
+		days[t.Format(time.DateOnly)] = true
+	}
+	return len(days) > 1
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	points := []PageView{
+		// Monday 2025-01-06 08:00 UTC is 09:00 in Berlin
+		{T: "2025-01-06T08:00:00Z", Y: 5},
+		{T: "2025-01-13T08:00:00Z", Y: 7},
+		// Bare labels are wall-clock time in the requested timezone
+		{X: "2025-01-11 23:00:00", Y: 3},
+		{T: "not-a-date", Y: 100},
+	}
+
+	heatmap, err := buildHeatmap(points, berlin, "visitors")
+	if err != nil {
+		t.Fatalf("buildHeatmap failed: %v", err)
+	}
+
+	if heatmap.Matrix[0][9] != 12 {
+		t.Errorf("Expected 12 visitors on Monday 09:00, got %d", heatmap.Matrix[0][9])
+	}
+	if heatmap.Matrix[5][23] != 3 {
+		t.Errorf("Expected 3 visitors on Saturday 23:00, got %d", heatmap.Matrix[5][23])
+	}
+	if heatmap.Total != 15 {
+		t.Errorf("Expected total 15, got %d", heatmap.Total)
+	}
+	if heatmap.Peak != (HeatmapSlot{Day: "Monday", Hour: 9, Value: 12}) {
+		t.Errorf("Unexpected peak %+v", heatmap.Peak)
+	}
+	if heatmap.Trough != (HeatmapSlot{Day: "Monday", Hour: 0, Value: 0}) {
+		t.Errorf("Unexpected trough %+v", heatmap.Trough)
+	}
+}
+
+func TestBuildHeatmap_RejectsDailySeries(t *testing.T) {
+	points := []PageView{
+		{X: "2025-01-06 00:00:00", Y: 40},
+		{X: "2025-01-07 00:00:00", Y: 35},
+		{X: "2025-01-08 00:00:00", Y: 52},
+	}
+
+	if _, err := buildHeatmap(points, time.UTC, "visitors"); err != errDailySeries {
+		t.Errorf("Expected errDailySeries, got %v", err)
+	}
+}
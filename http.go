@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -17,6 +19,69 @@ const maxBodySize = 1 << 20 // 1 MB
 
 type session struct {
 	server *MCPServer
+
+	mu      sync.Mutex
+	streams map[*sseStream]struct{}
+}
+
+// sseStream is a POST response upgraded to Server-Sent Events so
+// notifications can be delivered before the JSON-RPC response.
+type sseStream struct {
+	mu      sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (st *sseStream) write(msg any) {
+	data, _ := json.Marshal(msg)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, _ = fmt.Fprintf(st.w, "event: message\ndata: %s\n\n", data)
+	st.flusher.Flush()
+}
+
+// publish sends a notification to every open stream of the session. It is
+// dropped when none is open, since plain JSON responses cannot carry it.
+func (sess *session) publish(n Notification) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	for st := range sess.streams {
+		st.write(n)
+	}
+}
+
+// respond writes the result of handle as JSON, or as an SSE stream carrying
+// the session's log notifications when the client enabled logging and
+// accepts text/event-stream.
+func (sess *session) respond(w http.ResponseWriter, r *http.Request, handle func() any) {
+	flusher, canFlush := w.(http.Flusher)
+	if !canFlush || sess.server.logLevel.Load() == 0 ||
+		!strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(handle())
+		_, _ = w.Write(data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	st := &sseStream{w: w, flusher: flusher}
+	sess.mu.Lock()
+	if sess.streams == nil {
+		sess.streams = make(map[*sseStream]struct{})
+	}
+	sess.streams[st] = struct{}{}
+	sess.mu.Unlock()
+
+	result := handle()
+
+	sess.mu.Lock()
+	delete(sess.streams, st)
+	sess.mu.Unlock()
+
+	st.write(result)
 }
 
 type HTTPHandler struct {
@@ -51,7 +116,8 @@ func (h *HTTPHandler) setCORS(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Access-Control-Allow-Headers",
 		"Content-Type, Authorization, Mcp-Session-Id, "+
-			"X-Umami-Host, X-Umami-Username, X-Umami-Password, X-Umami-Api-Key, X-Umami-Team-Id")
+			"X-Umami-Host, X-Umami-Username, X-Umami-Password, X-Umami-Api-Key, X-Umami-Team-Id, "+
+			"X-Umami-Allow-Writes")
 	w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
 }
 
@@ -83,6 +149,11 @@ func (h *HTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		h.handleBatch(w, r, trimmed)
+		return
+	}
+
 	var msg struct {
 		ID     any    `json:"id"`
 		Method string `json:"method"`
@@ -121,11 +192,55 @@ func (h *HTTPHandler) handlePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sess := val.(*session)
-	resp := sess.server.HandleRequest(req)
+	sess.respond(w, r, func() any { return sess.server.handleInOrder(req) })
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	data, _ := json.Marshal(resp)
-	_, _ = w.Write(data)
+// handleBatch serves a JSON-RPC batch within an existing session. A batch of
+// only notifications is acknowledged with 202 like a single notification.
+func (h *HTTPHandler) handleBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		writeJSONRPCError(w, nil, &Error{Code: -32700, Message: "Parse error"})
+		return
+	}
+	if len(batch) == 0 {
+		writeJSONRPCError(w, nil, &Error{Code: -32600, Message: "Invalid Request"})
+		return
+	}
+
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		http.Error(w, "Missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	}
+
+	val, ok := h.sessions.Load(sessionID)
+	if !ok {
+		http.Error(w, "Invalid session", http.StatusNotFound)
+		return
+	}
+
+	sess := val.(*session)
+	if !hasRequest(batch) {
+		sess.server.HandleBatch(batch)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	sess.respond(w, r, func() any { return sess.server.HandleBatch(batch) })
+}
+
+// hasRequest reports whether a batch contains anything that needs a response.
+func hasRequest(batch []json.RawMessage) bool {
+	for _, rawMsg := range batch {
+		var msg struct {
+			ID any `json:"id"`
+		}
+		if err := json.Unmarshal(rawMsg, &msg); err != nil || msg.ID != nil {
+			return true
+		}
+	}
+	return false
 }
 
 type umamiCreds struct {
@@ -218,12 +333,23 @@ func (h *HTTPHandler) handleInitialize(w http.ResponseWriter, r *http.Request, r
 		return
 	}
 
-	sessionID := generateSessionID()
 	srv := NewMCPServer(client)
-	h.sessions.Store(sessionID, &session{server: srv})
-	h.sessionCount.Add(1)
+	srv.allowWrites, _ = strconv.ParseBool(r.Header.Get("X-Umami-Allow-Writes"))
 
 	resp := srv.HandleRequest(req)
+	if resp.Error != nil {
+		writeJSONRPCError(w, req.ID, resp.Error)
+		return
+	}
+	// The session ID gates every later request, so the server is ready as
+	// soon as initialize succeeds rather than on notifications/initialized.
+	srv.state.Store(int32(stateReady))
+
+	sessionID := generateSessionID()
+	sess := &session{server: srv}
+	srv.notify = sess.publish
+	h.sessions.Store(sessionID, sess)
+	h.sessionCount.Add(1)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Mcp-Session-Id", sessionID)
@@ -113,6 +113,34 @@ func TestHTTP_InitializeMissingCredentials(t *testing.T) {
 	}
 }
 
+func TestHTTP_InitializeUnsupportedVersion(t *testing.T) {
+	umami := setupTestUmamiServer()
+	defer umami.Close()
+
+	handler := NewHTTPHandler(nil, 0)
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2023-01-01"}}`
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("X-Umami-Host", umami.URL)
+	req.Header.Set("X-Umami-Username", "admin")
+	req.Header.Set("X-Umami-Password", "pass")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected -32602 error, got: %v", resp.Error)
+	}
+	if w.Header().Get("Mcp-Session-Id") != "" {
+		t.Error("Expected no session for a rejected initialize")
+	}
+	if n := handler.sessionCount.Load(); n != 0 {
+		t.Errorf("Expected 0 sessions, got %d", n)
+	}
+}
+
 func TestHTTP_ToolsList(t *testing.T) {
 	umami := setupTestUmamiServer()
 	defer umami.Close()
@@ -243,16 +271,16 @@ func TestHTTP_ServerCard(t *testing.T) {
 	if err := json.Unmarshal(card["tools"], &tools); err != nil {
 		t.Fatalf("Failed to parse tools: %v", err)
 	}
-	if len(tools) != 5 {
-		t.Errorf("Expected 5 tools, got %d", len(tools))
+	if len(tools) != 35 {
+		t.Errorf("Expected 35 tools, got %d", len(tools))
 	}
 
 	var prompts []json.RawMessage
 	if err := json.Unmarshal(card["prompts"], &prompts); err != nil {
 		t.Fatalf("Failed to parse prompts: %v", err)
 	}
-	if len(prompts) != 4 {
-		t.Errorf("Expected 4 prompts, got %d", len(prompts))
+	if len(prompts) != 5 {
+		t.Errorf("Expected 5 prompts, got %d", len(prompts))
 	}
 }
 
@@ -378,3 +406,119 @@ func TestParseOrigins(t *testing.T) {
 		}
 	}
 }
+
+func TestHTTP_AllowWritesHeader(t *testing.T) {
+	umami := setupTestUmamiServer()
+	defer umami.Close()
+
+	handler := NewHTTPHandler(nil, 0)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize"}`
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("X-Umami-Host", umami.URL)
+	req.Header.Set("X-Umami-Username", "admin")
+	req.Header.Set("X-Umami-Password", "pass")
+	req.Header.Set("X-Umami-Allow-Writes", "true")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	writable, ok := handler.sessions.Load(w.Header().Get("Mcp-Session-Id"))
+	if !ok {
+		t.Fatal("Session not stored")
+	}
+	if !writable.(*session).server.allowWrites {
+		t.Error("Expected X-Umami-Allow-Writes: true to enable write mode")
+	}
+
+	readOnly, _ := handler.sessions.Load(initializeSession(t, handler, umami.URL))
+	if readOnly.(*session).server.allowWrites {
+		t.Error("Expected write mode to be off without the header")
+	}
+}
+
+func TestHTTP_Batch(t *testing.T) {
+	umami := setupTestUmamiServer()
+	defer umami.Close()
+
+	handler := NewHTTPHandler(nil, 0)
+	sessionID := initializeSession(t, handler, umami.URL)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`[{"jsonrpc":"2.0","id":2,"method":"tools/list"},` +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"},` +
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var responses []Response
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("Expected a JSON array, got %s", w.Body.String())
+	}
+	if len(responses) != 2 || responses[0].ID != float64(2) || responses[1].ID != float64(3) {
+		t.Errorf("Expected responses for ids 2 and 3 in order, got %+v", responses)
+	}
+
+	w = post(`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification-only batch, got %d", w.Code)
+	}
+
+	w = post(`[]`)
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("Expected invalid request for an empty batch, got %s", w.Body.String())
+	}
+}
+
+func TestHTTP_LogNotificationsOverSSE(t *testing.T) {
+	umami := setupTestUmamiServer()
+	defer umami.Close()
+
+	handler := NewHTTPHandler(nil, 0)
+	sessionID := initializeSession(t, handler, umami.URL)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	callStats := `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_stats",` +
+		`"arguments":{"website_id":"abc123","start_date":"2025-01-01","end_date":"2025-01-31"}}}`
+
+	// Without logging enabled the response stays plain JSON
+	if w := post(callStats); w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected JSON before logging/setLevel, got %s", w.Header().Get("Content-Type"))
+	}
+
+	if w := post(`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"error"}}`); w.Code != 200 {
+		t.Fatalf("logging/setLevel returned %d", w.Code)
+	}
+
+	w := post(callStats)
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an SSE response, got %s", w.Header().Get("Content-Type"))
+	}
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	if len(events) != 2 {
+		t.Fatalf("Expected a log event and the response, got:\n%s", w.Body.String())
+	}
+	if !strings.Contains(events[0], `"method":"notifications/message"`) || !strings.Contains(events[0], "404") {
+		t.Errorf("Expected upstream error notification first, got %s", events[0])
+	}
+	if !strings.Contains(events[1], `"id":3`) {
+		t.Errorf("Expected the JSON-RPC response last, got %s", events[1])
+	}
+}
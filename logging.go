@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// logLevels are the MCP (syslog) log levels from least to most severe.
+var logLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// log sends a diagnostic to the client as notifications/message when the
+// client has enabled logging at level or below.
+func (s *MCPServer) log(level, message string) {
+	threshold := int(s.logLevel.Load()) - 1
+	if threshold < 0 || s.notify == nil || slices.Index(logLevels, level) < threshold {
+		return
+	}
+
+	s.notify(Notification{
+		JSONRPC: "2.0",
+		Method:  "notifications/message",
+		Params: map[string]any{
+			"level":  level,
+			"logger": "umami",
+			"data":   message,
+		},
+	})
+}
+
+func (s *MCPServer) processSetLevel(rawParams json.RawMessage) (any, *Error) {
+	var params struct {
+		Level string `json:"level"`
+	}
+
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, &Error{Code: -32602, Message: "Invalid params"}
+	}
+
+	index := slices.Index(logLevels, params.Level)
+	if index < 0 {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Invalid log level: %q", params.Level)}
+	}
+
+	// Stored off by one so the zero value means logging is disabled
+	s.logLevel.Store(int32(index + 1))
+	return map[string]any{}, nil
+}
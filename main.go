@@ -30,9 +30,16 @@ type Response struct {
 	Error   *Error `json:"error,omitempty"`
 }
 
+type Notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
 }
 
 func main() {
@@ -91,6 +98,7 @@ func main() {
 		}
 
 		server := NewMCPServer(client)
+		server.allowWrites = config.AllowWrites
 		if err := server.Run(); err != nil {
 			log.Fatalf("Server error: %v", err)
 		}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 //go:embed tools.json
@@ -17,48 +20,141 @@ var toolsFS embed.FS
 var promptsFS embed.FS
 
 type MCPServer struct {
-	client *UmamiClient
-	stdin  io.Reader
-	stdout io.Writer
+	client      *UmamiClient
+	stdin       io.Reader
+	stdout      io.Writer
+	allowWrites bool
+
+	// protocolVersion and clientCapabilities are agreed during initialize.
+	protocolVersion    string
+	clientCapabilities map[string]json.RawMessage
+	// state is the connection's lifecycleState.
+	state atomic.Int32
+
+	// logLevel is the index of the minimum level in logLevels plus one;
+	// zero until the client enables logging with logging/setLevel.
+	logLevel atomic.Int32
+	// notify delivers server-initiated notifications to the client.
+	notify func(Notification)
+	// outMu serializes writes to stdout from concurrent tool calls.
+	outMu sync.Mutex
 }
 
 func NewMCPServer(client *UmamiClient) *MCPServer {
-	return &MCPServer{
+	s := &MCPServer{
 		client: client,
 		stdin:  os.Stdin,
 		stdout: os.Stdout,
 	}
+	s.notify = s.sendNotification
+	client.logger = s.log
+	return s
 }
 
 func (s *MCPServer) Run() error {
 	scanner := bufio.NewScanner(s.stdin)
 	for scanner.Scan() {
-		var rawMsg json.RawMessage
-		if err := json.Unmarshal(scanner.Bytes(), &rawMsg); err != nil {
-			s.send(Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}})
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
 			continue
 		}
 
-		var msgType struct {
+		if line[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(line, &batch); err != nil {
+				s.send(Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}})
+				continue
+			}
+			if len(batch) == 0 {
+				s.send(Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32600, Message: "Invalid Request"}})
+				continue
+			}
+			if responses := s.HandleBatch(batch); len(responses) > 0 {
+				s.sendBatch(responses)
+			}
+			continue
+		}
+
+		if resp := s.handleMessage(line); resp != nil {
+			s.send(*resp)
+		}
+	}
+	return scanner.Err()
+}
+
+// handleMessage dispatches a single JSON-RPC message and advances the
+// lifecycle. Notifications (no id) produce no response; requests out of
+// lifecycle order are rejected before they reach HandleRequest.
+func (s *MCPServer) handleMessage(rawMsg json.RawMessage) *Response {
+	var msgType struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(rawMsg, &msgType); err != nil {
+		return &Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}}
+	}
+
+	if msgType.ID == nil {
+		if msgType.Method == "notifications/initialized" {
+			s.state.CompareAndSwap(int32(stateInitializing), int32(stateReady))
+		}
+		return nil
+	}
+
+	var req Request
+	if err := json.Unmarshal(rawMsg, &req); err != nil {
+		return &Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}}
+	}
+
+	resp := s.handleInOrder(req)
+	return &resp
+}
+
+// handleInOrder serves a request that arrived on a transport, rejecting it if
+// it is out of lifecycle order and advancing the lifecycle on initialize.
+func (s *MCPServer) handleInOrder(req Request) Response {
+	if rpcErr := s.admit(req.Method); rpcErr != nil {
+		return Response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+
+	resp := s.HandleRequest(req)
+	if req.Method == "initialize" && resp.Error == nil {
+		s.state.Store(int32(stateInitializing))
+	}
+	return resp
+}
+
+// HandleBatch dispatches every element of a JSON-RPC batch in order and
+// returns the responses to its requests. Elements that are not objects get
+// an Invalid Request error, and initialize may not be batched.
+func (s *MCPServer) HandleBatch(batch []json.RawMessage) []Response {
+	responses := make([]Response, 0, len(batch))
+	for _, rawMsg := range batch {
+		var msg struct {
 			ID     any    `json:"id"`
 			Method string `json:"method"`
 		}
-		if err := json.Unmarshal(rawMsg, &msgType); err != nil {
-			s.send(Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}})
+		if err := json.Unmarshal(rawMsg, &msg); err != nil {
+			responses = append(responses, Response{
+				JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32600, Message: "Invalid Request"},
+			})
 			continue
 		}
 
-		if msgType.ID != nil {
-			var req Request
-			if err := json.Unmarshal(rawMsg, &req); err != nil {
-				s.send(Response{JSONRPC: "2.0", ID: nil, Error: &Error{Code: -32700, Message: "Parse error"}})
-				continue
+		if msg.Method == "initialize" {
+			if msg.ID != nil {
+				responses = append(responses, Response{
+					JSONRPC: "2.0", ID: msg.ID, Error: &Error{Code: -32600, Message: "initialize must not be batched"},
+				})
 			}
-			s.send(s.HandleRequest(req))
+			continue
+		}
+
+		if resp := s.handleMessage(rawMsg); resp != nil {
+			responses = append(responses, *resp)
 		}
-		// Notifications (no id): silently ignore
 	}
-	return scanner.Err()
+	return responses
 }
 
 func (s *MCPServer) HandleRequest(req Request) Response {
@@ -67,7 +163,11 @@ func (s *MCPServer) HandleRequest(req Request) Response {
 
 	switch req.Method {
 	case "initialize":
-		result = s.processInitialize()
+		result, rpcErr = s.processInitialize(req.Params)
+	case "ping":
+		result = map[string]any{}
+	case "logging/setLevel":
+		result, rpcErr = s.processSetLevel(req.Params)
 	case "tools/list":
 		result, rpcErr = s.processToolsList()
 	case "tools/call":
@@ -91,23 +191,22 @@ func (s *MCPServer) HandleRequest(req Request) Response {
 }
 
 func (s *MCPServer) send(resp Response) {
-	data, _ := json.Marshal(resp)
-	_, _ = fmt.Fprintf(s.stdout, "%s\n", data)
+	s.writeLine(resp)
 }
 
-func (s *MCPServer) processInitialize() any {
-	return map[string]any{
-		"protocolVersion": "2025-03-26",
-		"serverInfo": map[string]string{
-			"name":    "umami-mcp",
-			"version": version,
-		},
-		"capabilities": map[string]any{
-			"tools":     map[string]any{},
-			"prompts":   map[string]any{},
-			"resources": map[string]any{},
-		},
-	}
+func (s *MCPServer) sendBatch(responses []Response) {
+	s.writeLine(responses)
+}
+
+func (s *MCPServer) sendNotification(n Notification) {
+	s.writeLine(n)
+}
+
+func (s *MCPServer) writeLine(msg any) {
+	data, _ := json.Marshal(msg)
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	_, _ = fmt.Fprintf(s.stdout, "%s\n", data)
 }
 
 func (s *MCPServer) processToolsList() (any, *Error) {
@@ -121,9 +220,75 @@ func (s *MCPServer) processToolsList() (any, *Error) {
 		return nil, &Error{Code: -32603, Message: fmt.Sprintf("Failed to parse tools: %v", err)}
 	}
 
+	if !s.allowWrites {
+		readOnly := make([]map[string]any, 0, len(tools))
+		for _, tool := range tools {
+			if name, _ := tool["name"].(string); !writeTools[name] {
+				readOnly = append(readOnly, tool)
+			}
+		}
+		tools = readOnly
+	}
+
+	if !s.supportsStructuredOutput() {
+		for _, tool := range tools {
+			delete(tool, "outputSchema")
+		}
+	}
+
 	return map[string]any{"tools": tools}, nil
 }
 
+// writeTools modify data in Umami and are only exposed when writes are allowed.
+var writeTools = map[string]bool{
+	"create_website":     true,
+	"update_website":     true,
+	"reset_website_data": true,
+	"delete_website":     true,
+	"enable_share_link":  true,
+	"rotate_share_link":  true,
+	"disable_share_link": true,
+}
+
+// toolHandlers maps every tool name to the handler that runs it.
+var toolHandlers = map[string]func(*MCPServer, json.RawMessage) (any, *Error){
+	"get_websites":           (*MCPServer).execGetWebsites,
+	"get_teams":              (*MCPServer).execGetTeams,
+	"get_team_members":       (*MCPServer).execGetTeamMembers,
+	"get_team_websites":      (*MCPServer).execGetTeamWebsites,
+	"get_users":              (*MCPServer).execGetUsers,
+	"list_segments":          (*MCPServer).execListSegments,
+	"get_segment":            (*MCPServer).execGetSegment,
+	"get_stats":              (*MCPServer).execGetStats,
+	"get_portfolio_stats":    (*MCPServer).execGetPortfolioStats,
+	"batch_query":            (*MCPServer).execBatchQuery,
+	"get_pageviews":          (*MCPServer).execGetPageViews,
+	"get_traffic_heatmap":    (*MCPServer).execGetTrafficHeatmap,
+	"get_metrics":            (*MCPServer).execGetMetrics,
+	"get_active":             (*MCPServer).execGetActive,
+	"get_realtime":           (*MCPServer).execGetRealtime,
+	"get_events":             (*MCPServer).execGetEvents,
+	"get_event_properties":   (*MCPServer).execGetEventProperties,
+	"get_sessions":           (*MCPServer).execGetSessions,
+	"get_session_activity":   (*MCPServer).execGetSessionActivity,
+	"get_session_properties": (*MCPServer).execGetSessionProperties,
+	"lookup_distinct_id":     (*MCPServer).execLookupDistinctID,
+	"get_funnel":             (*MCPServer).execGetFunnel,
+	"get_retention":          (*MCPServer).execGetRetention,
+	"get_journey":            (*MCPServer).execGetJourney,
+	"get_utm":                (*MCPServer).execGetUTM,
+	"get_attribution":        (*MCPServer).execGetAttribution,
+	"get_revenue":            (*MCPServer).execGetRevenue,
+	"get_goals":              (*MCPServer).execGetGoals,
+	"create_website":         (*MCPServer).execCreateWebsite,
+	"update_website":         (*MCPServer).execUpdateWebsite,
+	"reset_website_data":     (*MCPServer).execResetWebsiteData,
+	"delete_website":         (*MCPServer).execDeleteWebsite,
+	"enable_share_link":      (*MCPServer).execEnableShareLink,
+	"rotate_share_link":      (*MCPServer).execRotateShareLink,
+	"disable_share_link":     (*MCPServer).execDisableShareLink,
+}
+
 func (s *MCPServer) processToolCall(rawParams json.RawMessage) (any, *Error) {
 	var params struct {
 		Name      string          `json:"name"`
@@ -134,20 +299,26 @@ func (s *MCPServer) processToolCall(rawParams json.RawMessage) (any, *Error) {
 		return nil, &Error{Code: -32602, Message: "Invalid params"}
 	}
 
-	switch params.Name {
-	case "get_websites":
-		return s.execGetWebsites(params.Arguments)
-	case "get_stats":
-		return s.execGetStats(params.Arguments)
-	case "get_pageviews":
-		return s.execGetPageViews(params.Arguments)
-	case "get_metrics":
-		return s.execGetMetrics(params.Arguments)
-	case "get_active":
-		return s.execGetActive(params.Arguments)
-	default:
+	if writeTools[params.Name] && !s.allowWrites {
+		return nil, &Error{
+			Code:    -32602,
+			Message: fmt.Sprintf("Tool %s modifies data and requires write mode to be enabled", params.Name),
+		}
+	}
+
+	handler, ok := toolHandlers[params.Name]
+	if !ok {
 		return nil, &Error{Code: -32602, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}
 	}
+
+	result, rpcErr := handler(s, params.Arguments)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if response, ok := result.(map[string]any); ok && !s.supportsStructuredOutput() {
+		delete(response, "structuredContent")
+	}
+	return result, nil
 }
 
 func (s *MCPServer) processPromptsList() (any, *Error) {
@@ -167,12 +338,13 @@ func (s *MCPServer) processPromptsList() (any, *Error) {
 var promptTemplates = map[string]string{
 	"analytics-report": "First call get_websites to find the target website. " +
 		"Then generate a comprehensive analytics report " +
-		"covering the last {days} days by calling:\n" +
-		"- get_stats for overall visitor metrics\n" +
-		"- get_pageviews (unit: day) for traffic trends\n" +
-		"- get_metrics for top pages (type: path), referrers (type: referrer), " +
-		"countries (type: country), browsers (type: browser), " +
-		"and devices (type: device)\n\n" +
+		"covering the last {days} days. Fetch everything in a single batch_query call " +
+		"with website_id, start_date and end_date in defaults and these queries:\n" +
+		"- stats for overall visitor metrics\n" +
+		"- pageviews (unit: day) for traffic trends\n" +
+		"- metrics for top pages (metric_type: path), referrers (metric_type: referrer), " +
+		"countries (metric_type: country), browsers (metric_type: browser), " +
+		"and devices (metric_type: device)\n\n" +
 		"Summarize the findings in a clear, well-structured report.",
 
 	"top-pages": "First call get_websites to find the target website. " +
@@ -190,15 +362,28 @@ var promptTemplates = map[string]string{
 		"Present the breakdown with counts and percentages.",
 
 	"realtime-check": "First call get_websites to find the target website. " +
-		"Then call get_active to check the current number of active visitors. " +
-		"Report the real-time visitor count.",
+		"Then call get_realtime to see the current active visitors and activity " +
+		"over the last 30 minutes. " +
+		"Report the real-time visitor count, the pages they are viewing, " +
+		"where they came from, and any recent custom events.",
+
+	"funnel-analysis": "First call get_websites to find the target website. " +
+		"Then build a conversion funnel over the last {days} days for these steps, in order: {steps}.\n" +
+		"- Turn each step into {\"type\": \"path\", \"value\": \"/some/page\"} for pages " +
+		"or {\"type\": \"event\", \"value\": \"event-name\"} for custom events. " +
+		"If unsure whether a step is a page or an event, check get_metrics (type: path) " +
+		"or get_events first\n" +
+		"- Call get_funnel with the steps and a conversion window of {window} minutes\n\n" +
+		"Present each step with visitors, drop-off and conversion, " +
+		"and point out the step with the largest drop-off.",
 }
 
 var promptDefaults = map[string]map[string]string{
 	"analytics-report": {"days": "30"},
 	"top-pages":        {"days": "7", "limit": "10"},
-	"visitor-insights":  {"days": "30"},
-	"realtime-check":    {},
+	"visitor-insights": {"days": "30"},
+	"realtime-check":   {},
+	"funnel-analysis":  {"days": "7", "window": "60"},
 }
 
 func (s *MCPServer) processPromptsGet(rawParams json.RawMessage) (any, *Error) {
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -37,6 +39,79 @@ func TestMCPServer_HandleInitialize(t *testing.T) {
 	}
 }
 
+func TestMCPServer_InitializeNegotiatesVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+	}{
+		{"omitted", "", defaultProtocolVersion},
+		{"exact match", "2024-11-05", "2024-11-05"},
+		{"latest", "2025-06-18", "2025-06-18"},
+		{"newer client", "2099-01-01", "2025-06-18"},
+		{"between revisions", "2025-05-01", "2025-03-26"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &MCPServer{client: &UmamiClient{}}
+			params, _ := json.Marshal(map[string]any{"protocolVersion": tt.requested})
+
+			resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: params})
+
+			if resp.Error != nil {
+				t.Fatalf("Unexpected error: %v", resp.Error)
+			}
+			result := resp.Result.(map[string]any)
+			if result["protocolVersion"] != tt.want {
+				t.Errorf("Expected %q, got %v", tt.want, result["protocolVersion"])
+			}
+			if server.protocolVersion != tt.want {
+				t.Errorf("Expected stored version %q, got %q", tt.want, server.protocolVersion)
+			}
+		})
+	}
+}
+
+func TestMCPServer_InitializeRejectsUnsupportedVersion(t *testing.T) {
+	for _, requested := range []string{"2024-10-07", "v1"} {
+		t.Run(requested, func(t *testing.T) {
+			server := &MCPServer{client: &UmamiClient{}}
+			params, _ := json.Marshal(map[string]any{"protocolVersion": requested})
+
+			resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: params})
+
+			if resp.Error == nil || resp.Error.Code != -32602 {
+				t.Fatalf("Expected -32602 error, got: %v", resp.Error)
+			}
+			data, ok := resp.Error.Data.(map[string]any)
+			if !ok || data["requested"] != requested || data["supported"] == nil {
+				t.Errorf("Expected supported and requested versions in error data, got: %v", resp.Error.Data)
+			}
+			if server.protocolVersion != "" {
+				t.Errorf("Expected no negotiated version, got %q", server.protocolVersion)
+			}
+		})
+	}
+}
+
+func TestMCPServer_InitializeStoresClientCapabilities(t *testing.T) {
+	server := &MCPServer{client: &UmamiClient{}}
+	params := json.RawMessage(`{"protocolVersion":"2025-06-18","capabilities":{"roots":{"listChanged":true}}}`)
+
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	if _, ok := server.clientCapabilities["roots"]; !ok || len(server.clientCapabilities) != 1 {
+		t.Errorf("Expected only the roots capability to be recorded, got %v", server.clientCapabilities)
+	}
+	if !server.supportsStructuredOutput() {
+		t.Error("Expected structured output for 2025-06-18")
+	}
+}
+
 func TestMCPServer_HandleToolsList(t *testing.T) {
 	server := &MCPServer{client: &UmamiClient{}}
 
@@ -55,11 +130,28 @@ func TestMCPServer_HandleToolsList(t *testing.T) {
 		t.Fatal("Tools is not []map[string]any")
 	}
 
-	if len(toolsInterface) != 5 {
-		t.Fatalf("Expected 5 tools, got %d", len(toolsInterface))
+	if len(toolsInterface) != 28 {
+		t.Fatalf("Expected 28 tools, got %d", len(toolsInterface))
+	}
+
+	expectedTools := []string{
+		"get_websites", "get_stats", "get_pageviews", "get_metrics", "get_active", "get_events",
+		"get_sessions", "get_session_activity",
+		"get_funnel",
+		"get_retention",
+		"get_journey",
+		"get_utm", "get_attribution",
+		"get_revenue",
+		"get_goals",
+		"get_realtime",
+		"get_teams", "get_team_members", "get_team_websites", "get_users",
+		"list_segments", "get_segment",
+		"get_event_properties",
+		"get_session_properties", "lookup_distinct_id",
+		"get_traffic_heatmap",
+		"get_portfolio_stats",
+		"batch_query",
 	}
-
-	expectedTools := []string{"get_websites", "get_stats", "get_pageviews", "get_metrics", "get_active"}
 	for i, tool := range toolsInterface {
 		name, ok := tool["name"].(string)
 		if !ok {
@@ -104,8 +196,8 @@ func TestMCPServer_ToolsJSONValidity(t *testing.T) {
 		t.Fatalf("Failed to parse tools JSON: %v", err)
 	}
 
-	if len(tools) != 5 {
-		t.Fatalf("Expected 5 tools, got %d", len(tools))
+	if len(tools) != 35 {
+		t.Fatalf("Expected 35 tools, got %d", len(tools))
 	}
 
 	for i, tool := range tools {
@@ -115,6 +207,13 @@ func TestMCPServer_ToolsJSONValidity(t *testing.T) {
 		if !hasName || !hasDesc || !hasSchema {
 			t.Errorf("Tool %d missing required fields", i)
 		}
+		if name, _ := tool["name"].(string); toolHandlers[name] == nil {
+			t.Errorf("Tool %v has no handler", tool["name"])
+		}
+	}
+
+	if len(toolHandlers) != len(tools) {
+		t.Errorf("Expected %d tool handlers, got %d", len(tools), len(toolHandlers))
 	}
 }
 
@@ -137,11 +236,13 @@ func TestMCPServer_HandlePromptsList(t *testing.T) {
 		t.Fatal("Prompts is not []map[string]any")
 	}
 
-	if len(prompts) != 4 {
-		t.Fatalf("Expected 4 prompts, got %d", len(prompts))
+	if len(prompts) != 5 {
+		t.Fatalf("Expected 5 prompts, got %d", len(prompts))
 	}
 
-	expectedPrompts := []string{"analytics-report", "top-pages", "visitor-insights", "realtime-check"}
+	expectedPrompts := []string{
+		"analytics-report", "top-pages", "visitor-insights", "realtime-check", "funnel-analysis",
+	}
 	for i, prompt := range prompts {
 		name, ok := prompt["name"].(string)
 		if !ok {
@@ -337,3 +438,432 @@ func TestMCPServer_HandleResourcesRead(t *testing.T) {
 		t.Error("Expected resource content to contain website ID 'site1'")
 	}
 }
+
+func TestMCPServer_WriteToolsRequireWriteMode(t *testing.T) {
+	listTools := func(server *MCPServer) map[string]bool {
+		resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+		if resp.Error != nil {
+			t.Fatalf("Unexpected error: %v", resp.Error)
+		}
+		names := make(map[string]bool)
+		for _, tool := range resp.Result.(map[string]any)["tools"].([]map[string]any) {
+			names[tool["name"].(string)] = true
+		}
+		return names
+	}
+
+	readOnly := &MCPServer{client: &UmamiClient{}}
+	if tools := listTools(readOnly); tools["delete_website"] || tools["create_website"] {
+		t.Error("Write tools should not be listed without write mode")
+	}
+
+	params, _ := json.Marshal(map[string]any{
+		"name":      "create_website",
+		"arguments": map[string]string{"name": "Site", "domain": "example.com"},
+	})
+	resp := readOnly.HandleRequest(Request{JSONRPC: "2.0", ID: 2, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for write tool without write mode, got %v", resp.Error)
+	}
+
+	writable := &MCPServer{client: &UmamiClient{}, allowWrites: true}
+	for name := range writeTools {
+		if !listTools(writable)[name] {
+			t.Errorf("Expected %s to be listed in write mode", name)
+		}
+	}
+}
+
+func TestMCPServer_DestructiveToolsRequireConfirmation(t *testing.T) {
+	deleted := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.URL.Path == "/api/websites/abc123" {
+			deleted = true
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{
+		client:      &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}},
+		allowWrites: true,
+	}
+
+	call := func(confirm string) Response {
+		params, _ := json.Marshal(map[string]any{
+			"name":      "delete_website",
+			"arguments": map[string]string{"website_id": "abc123", "confirm": confirm},
+		})
+		return server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	}
+
+	if resp := call("abc124"); resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for mismatched confirmation, got %v", resp.Error)
+	}
+	if deleted {
+		t.Fatal("Website must not be deleted without matching confirmation")
+	}
+
+	if resp := call("abc123"); resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+	if !deleted {
+		t.Error("Expected DELETE request for confirmed deletion")
+	}
+}
+
+func TestMCPServer_EnableShareLinkKeepsExistingID(t *testing.T) {
+	posted := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posted = true
+		}
+		_, _ = fmt.Fprint(w, `{"id": "abc123", "shareId": "existingShareId1"}`)
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{
+		client:      &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}},
+		allowWrites: true,
+	}
+
+	params, _ := json.Marshal(map[string]any{
+		"name":      "enable_share_link",
+		"arguments": map[string]string{"website_id": "abc123"},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+	if posted {
+		t.Error("Enabling an already shared website should not rotate its share ID")
+	}
+
+	text := resp.Result.(map[string]any)["content"].([]map[string]string)[0]["text"]
+	var link ShareLink
+	if err := json.Unmarshal([]byte(text), &link); err != nil {
+		t.Fatalf("Failed to parse share link: %v", err)
+	}
+	if !link.Enabled || link.URL != ts.URL+"/share/existingShareId1" {
+		t.Errorf("Unexpected share link: %+v", link)
+	}
+}
+
+func TestMCPServer_SegmentIDForwarded(t *testing.T) {
+	segmentID := "550e8400-e29b-41d4-a716-446655440000"
+
+	for _, typ := range []string{"segment", "cohort"} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/segments/"+segmentID) {
+				_, _ = fmt.Fprintf(w, `{"id": %q, "type": %q, "name": "Saved"}`, segmentID, typ)
+				return
+			}
+			if got := r.URL.Query().Get(typ); got != segmentID {
+				t.Errorf("Expected %s=%s, got %q", typ, segmentID, got)
+			}
+			_, _ = fmt.Fprint(w, `[{"x": "/pricing", "y": 10}]`)
+		}))
+
+		server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+		params, _ := json.Marshal(map[string]any{
+			"name": "get_metrics",
+			"arguments": map[string]any{
+				"website_id":  "abc123",
+				"start_date":  "2025-01-01",
+				"end_date":    "2025-01-31",
+				"metric_type": "path",
+				"segment_id":  segmentID,
+			},
+		})
+		resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+		if resp.Error != nil {
+			t.Fatalf("Unexpected error for %s: %v", typ, resp.Error)
+		}
+		ts.Close()
+	}
+}
+
+func TestMCPServer_PortfolioSegmentIDRequiresOneWebsite(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected upstream request %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "get_portfolio_stats",
+		"arguments": map[string]any{
+			"website_ids": []string{"abc123", "def456"},
+			"start_date":  "2025-01-01",
+			"end_date":    "2025-01-31",
+			"segment_id":  "550e8400-e29b-41d4-a716-446655440000",
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected segment_id across two websites to be rejected, got %+v", resp)
+	}
+}
+
+func TestMCPServer_TrafficHeatmapRejectsDailyBuckets(t *testing.T) {
+	var windows int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		startAt, _ := strconv.ParseInt(query.Get("startAt"), 10, 64)
+		endAt, _ := strconv.ParseInt(query.Get("endAt"), 10, 64)
+		if query.Get("unit") != "hour" || endAt-startAt > maxHourlyRange.Milliseconds() {
+			t.Errorf("Expected hourly window of at most 48h, got %s", r.URL.RawQuery)
+		}
+		windows++
+		// An Umami that ignores the hourly unit answers with daily buckets
+		_, _ = fmt.Fprint(w, `{"pageviews": [], "sessions": [`+
+			`{"x": "2025-01-06 00:00:00", "y": 40}, {"x": "2025-01-07 00:00:00", "y": 35}]}`)
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "get_traffic_heatmap",
+		"arguments": map[string]any{
+			"website_id": "abc123",
+			"start_date": "2025-01-01",
+			"end_date":   "2025-01-07",
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != -32603 {
+		t.Fatalf("Expected an upstream error for daily buckets, got %+v", resp)
+	}
+	if windows != 3 {
+		t.Errorf("Expected six days to be fetched in 3 windows, got %d", windows)
+	}
+}
+
+func TestMCPServer_NegativeLimitUsesDefault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/realtime/"):
+			_, _ = fmt.Fprint(w, `{"events": [{"__type": "pageview", "createdAt": "2025-01-01T10:01:00Z"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/active"):
+			_, _ = fmt.Fprint(w, `{"x": 1}`)
+		default:
+			_, _ = fmt.Fprint(w, `[{"items": ["/", "/pricing"], "count": 3}]`)
+		}
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	for _, tool := range []string{"get_journey", "get_realtime"} {
+		params, _ := json.Marshal(map[string]any{
+			"name": tool,
+			"arguments": map[string]any{
+				"website_id": "abc123",
+				"start_date": "2025-01-01",
+				"end_date":   "2025-01-31",
+				"limit":      -1,
+			},
+		})
+		resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+		if resp.Error != nil {
+			t.Errorf("%s: unexpected error: %v", tool, resp.Error)
+		}
+	}
+}
+
+// handshake is the initialize exchange a stdio client sends before any other request.
+var handshake = []string{
+	`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+	`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+}
+
+// runScript feeds lines to Run over stdin and returns the non-empty output lines.
+func runScript(t *testing.T, server *MCPServer, lines ...string) []string {
+	t.Helper()
+	var out bytes.Buffer
+	server.stdin = strings.NewReader(strings.Join(lines, "\n"))
+	server.stdout = &out
+	if err := server.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	output := strings.TrimSpace(out.String())
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+func TestMCPServer_RunBatch(t *testing.T) {
+	script := append(handshake,
+		`[{"jsonrpc":"2.0","id":1,"method":"tools/list"},`+
+			`{"jsonrpc":"2.0","method":"notifications/initialized"},`+
+			`{"jsonrpc":"2.0","id":"b","method":"unknown"},`+
+			`42]`,
+		`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+		`[]`,
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/list"}`,
+	)
+
+	server := &MCPServer{client: &UmamiClient{}}
+	lines := runScript(t, server, script...)
+	if len(lines) != 4 {
+		t.Fatalf("Expected initialize, batch, empty batch error and single outputs, got %d:\n%s",
+			len(lines), strings.Join(lines, "\n"))
+	}
+	lines = lines[1:]
+	checkBatchResponses(t, lines[0])
+
+	var empty Response
+	if err := json.Unmarshal([]byte(lines[1]), &empty); err != nil || empty.Error == nil || empty.Error.Code != -32600 {
+		t.Errorf("Expected a single invalid request error for an empty batch, got %s", lines[1])
+	}
+
+	var single Response
+	if err := json.Unmarshal([]byte(lines[2]), &single); err != nil || single.ID != float64(2) {
+		t.Errorf("Expected single response for id 2, got %s", lines[2])
+	}
+}
+
+// checkBatchResponses checks the array answering the mixed batch sent by
+// TestMCPServer_RunBatch.
+func checkBatchResponses(t *testing.T, line string) {
+	t.Helper()
+
+	var batch []Response
+	if err := json.Unmarshal([]byte(line), &batch); err != nil {
+		t.Fatalf("Expected a JSON array for the batch, got %s", line)
+	}
+	if len(batch) != 3 {
+		t.Fatalf("Expected 3 responses (notification skipped), got %d", len(batch))
+	}
+	if batch[0].ID != float64(1) || batch[0].Error != nil {
+		t.Errorf("Expected tools/list result for id 1, got %+v", batch[0])
+	}
+	if batch[1].ID != "b" || batch[1].Error == nil || batch[1].Error.Code != -32601 {
+		t.Errorf("Expected method not found for id b, got %+v", batch[1])
+	}
+	if batch[2].ID != nil || batch[2].Error == nil || batch[2].Error.Code != -32600 {
+		t.Errorf("Expected invalid request for non-object element, got %+v", batch[2])
+	}
+}
+
+func TestMCPServer_RunEnforcesLifecycle(t *testing.T) {
+	server := &MCPServer{client: &UmamiClient{}}
+	lines := runScript(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"initialize","params":{"protocolVersion":"2020-01-01"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":6,"method":"logging/setLevel","params":{"level":"error"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":8,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`[{"jsonrpc":"2.0","id":9,"method":"prompts/list"}]`,
+	)
+
+	wantErr := []int{-32600, 0, -32602, 0, -32600, 0, 0, -32600, 0}
+	if len(lines) != len(wantErr) {
+		t.Fatalf("Expected %d responses, got %d:\n%s", len(wantErr), len(lines), strings.Join(lines, "\n"))
+	}
+	for i, line := range lines {
+		var resp Response
+		if strings.HasPrefix(line, "[") {
+			var batch []Response
+			if err := json.Unmarshal([]byte(line), &batch); err != nil || len(batch) != 1 {
+				t.Fatalf("Expected a single batch response, got %s", line)
+			}
+			resp = batch[0]
+		} else if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("Failed to parse response %s: %v", line, err)
+		}
+
+		code := 0
+		if resp.Error != nil {
+			code = resp.Error.Code
+		}
+		if code != wantErr[i] {
+			t.Errorf("Response %d: expected error code %d, got %s", i, wantErr[i], line)
+		}
+	}
+}
+
+func TestMCPServer_HandleBatchRejectsInitialize(t *testing.T) {
+	server := &MCPServer{client: &UmamiClient{}}
+
+	responses := server.HandleBatch([]json.RawMessage{
+		json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`),
+	})
+	if len(responses) != 1 || responses[0].Error == nil || responses[0].Error.Code != -32600 {
+		t.Errorf("Expected initialize in a batch to be rejected, got %+v", responses)
+	}
+}
+
+func TestMCPServer_PingAndSetLevel(t *testing.T) {
+	server := &MCPServer{client: &UmamiClient{}}
+
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "ping"})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error for ping: %v", resp.Error)
+	}
+	if result, ok := resp.Result.(map[string]any); !ok || len(result) != 0 {
+		t.Errorf("Expected empty result for ping, got %v", resp.Result)
+	}
+
+	resp = server.HandleRequest(Request{
+		JSONRPC: "2.0", ID: 2, Method: "logging/setLevel", Params: json.RawMessage(`{"level":"verbose"}`),
+	})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for unknown level, got %v", resp.Error)
+	}
+
+	var notes []Notification
+	server.notify = func(n Notification) { notes = append(notes, n) }
+
+	server.log("error", "before logging is enabled")
+	resp = server.HandleRequest(Request{
+		JSONRPC: "2.0", ID: 3, Method: "logging/setLevel", Params: json.RawMessage(`{"level":"warning"}`),
+	})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+	server.log("info", "below the level")
+	server.log("error", "upstream failed")
+
+	if len(notes) != 1 || notes[0].Method != "notifications/message" {
+		t.Fatalf("Expected one notification, got %+v", notes)
+	}
+	params := notes[0].Params.(map[string]any)
+	if params["level"] != "error" || params["data"] != "upstream failed" {
+		t.Errorf("Unexpected notification params: %v", params)
+	}
+}
+
+func TestMCPServer_RunEmitsLogNotifications(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	server := NewMCPServer(&UmamiClient{baseURL: ts.URL, apiKey: "key", httpClient: &http.Client{}})
+	lines := runScript(t, server, append(handshake,
+		`{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"error"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_websites","arguments":{}}}`,
+	)...)
+	if len(lines) != 4 {
+		t.Fatalf("Expected initialize, setLevel, notification and tool error, got:\n%s", strings.Join(lines, "\n"))
+	}
+	lines = lines[1:]
+
+	var note Notification
+	if err := json.Unmarshal([]byte(lines[1]), &note); err != nil || note.Method != "notifications/message" {
+		t.Errorf("Expected log notification before the tool response, got %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"id":2`) {
+		t.Errorf("Expected tool response last, got %s", lines[2])
+	}
+}
@@ -31,3 +31,13 @@ func normalizeDate(input string) string {
 
 	return input
 }
+
+// reportDate converts a tool date argument into the ISO 8601 form expected
+// in the body of Umami report requests.
+func reportDate(input string) string {
+	ms, err := strconv.ParseInt(normalizeDate(input), 10, 64)
+	if err != nil {
+		return strings.TrimSpace(input)
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
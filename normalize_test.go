@@ -120,3 +120,23 @@ func TestNormalizeDate_LLMBugScenario(t *testing.T) {
 		t.Errorf("normalizeDate(\"2026-03-23\") resolved to %s, want 2026-03-23", parsed.Format("2006-01-02"))
 	}
 }
+
+func TestReportDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2026-03-23", "2026-03-23T00:00:00Z"},
+		{"2026-03-23T10:30:00+02:00", "2026-03-23T08:30:00Z"},
+		{"1774224000000", "2026-03-23T00:00:00Z"},
+		{"", ""},
+		{"not-a-date", "not-a-date"},
+	}
+
+	for _, tt := range tests {
+		result := reportDate(tt.input)
+		if result != tt.expected {
+			t.Errorf("reportDate(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import "sync"
+
+// forEachConcurrently calls fn for every index in [0, n) using at most
+// workers goroutines and returns once all calls have finished.
+func forEachConcurrently(n, workers int, fn func(i int)) {
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
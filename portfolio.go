@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// portfolioWorkers bounds how many websites are queried at once so large
+// portfolios don't flood the Umami instance.
+const portfolioWorkers = 5
+
+const (
+	portfolioSortTotal  = "total"
+	portfolioSortChange = "change"
+	portfolioSortGrowth = "growth"
+)
+
+type PortfolioSite struct {
+	Rank      int              `json:"rank,omitempty"`
+	WebsiteID string           `json:"websiteId"`
+	Name      string           `json:"name,omitempty"`
+	Domain    string           `json:"domain,omitempty"`
+	Current   *PeriodStats     `json:"current,omitempty"`
+	Previous  *PeriodStats     `json:"previous,omitempty"`
+	Change    map[string]Delta `json:"change,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+type PortfolioReport struct {
+	Metric string          `json:"metric"`
+	Sort   string          `json:"sort"`
+	Sites  []PortfolioSite `json:"sites"`
+	Failed []PortfolioSite `json:"failed,omitempty"`
+}
+
+// portfolioOptions applies the defaults for metric and order and checks
+// that both are supported.
+func portfolioOptions(metric, order string) (string, string, error) {
+	if metric == "" {
+		metric = "visitors"
+	}
+	if metric != "visitors" && metric != "pageviews" && metric != "visits" {
+		return "", "", errors.New("metric must be visitors, pageviews or visits")
+	}
+
+	if order == "" {
+		order = portfolioSortTotal
+	}
+	if order != portfolioSortTotal && order != portfolioSortChange && order != portfolioSortGrowth {
+		return "", "", errors.New("sort must be total, change or growth")
+	}
+
+	return metric, order, nil
+}
+
+// selectWebsites returns the websites named by ids in that order, or all of
+// them when ids is empty. IDs missing from all are kept without a name.
+func selectWebsites(all []Website, ids []string) []Website {
+	if len(ids) == 0 {
+		return all
+	}
+
+	known := make(map[string]Website, len(all))
+	for _, w := range all {
+		known[w.ID] = w
+	}
+	websites := make([]Website, 0, len(ids))
+	for _, id := range ids {
+		website, ok := known[id]
+		if !ok {
+			website = Website{ID: id}
+		}
+		websites = append(websites, website)
+	}
+	return websites
+}
+
+// fetchPortfolio runs fetch for every website on a bounded pool of workers.
+// Results keep the order of websites; a failing site only records its error.
+func fetchPortfolio(websites []Website, workers int, fetch func(Website) (*StatsReport, error)) []PortfolioSite {
+	sites := make([]PortfolioSite, len(websites))
+	forEachConcurrently(len(websites), workers, func(i int) {
+		website := websites[i]
+		site := PortfolioSite{WebsiteID: website.ID, Name: website.Name, Domain: website.Domain}
+		if report, err := fetch(website); err != nil {
+			site.Error = err.Error()
+		} else {
+			site.Current = &report.Current
+			site.Previous = &report.Previous
+			site.Change = report.Change
+		}
+		sites[i] = site
+	})
+	return sites
+}
+
+func periodMetric(p *PeriodStats, metric string) int {
+	switch metric {
+	case "pageviews":
+		return p.PageViews
+	case "visits":
+		return p.Visits
+	default:
+		return p.Visitors
+	}
+}
+
+// portfolioScore is the value a site is ranked by. Growth is undefined for
+// sites without previous traffic, which therefore rank last.
+func portfolioScore(site *PortfolioSite, metric, order string) float64 {
+	switch order {
+	case portfolioSortChange:
+		return site.Change[metric].Absolute
+	case portfolioSortGrowth:
+		if pct := site.Change[metric].Percentage; pct != nil {
+			return *pct
+		}
+		return math.Inf(-1)
+	default:
+		return float64(periodMetric(site.Current, metric))
+	}
+}
+
+// rankPortfolio orders the successful sites by metric and moves failed sites
+// into their own list.
+func rankPortfolio(sites []PortfolioSite, metric, order string) PortfolioReport {
+	report := PortfolioReport{Metric: metric, Sort: order, Sites: []PortfolioSite{}}
+	for _, site := range sites {
+		if site.Error != "" {
+			report.Failed = append(report.Failed, site)
+		} else {
+			report.Sites = append(report.Sites, site)
+		}
+	}
+
+	sort.SliceStable(report.Sites, func(i, j int) bool {
+		a := portfolioScore(&report.Sites[i], metric, order)
+		b := portfolioScore(&report.Sites[j], metric, order)
+		if a != b {
+			return a > b
+		}
+		return report.Sites[i].Name < report.Sites[j].Name
+	})
+	for i := range report.Sites {
+		report.Sites[i].Rank = i + 1
+	}
+
+	return report
+}
+
+// portfolioTable renders the ranked sites with the chosen metric for both
+// periods and the change between them.
+func portfolioTable(report PortfolioReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%4s %-32s %10s %10s %10s %8s\n", "rank", "website", report.Metric, "previous", "change", "change%")
+	for i := range report.Sites {
+		site := &report.Sites[i]
+		name := site.Name
+		if name == "" {
+			name = site.WebsiteID
+		}
+		change := site.Change[report.Metric]
+		pct := "-"
+		if change.Percentage != nil {
+			pct = fmt.Sprintf("%+.1f%%", *change.Percentage)
+		}
+		fmt.Fprintf(&b, "%4d %-32s %10d %10d %+10.0f %8s\n", site.Rank, name,
+			periodMetric(site.Current, report.Metric), periodMetric(site.Previous, report.Metric), change.Absolute, pct)
+	}
+	for _, site := range report.Failed {
+		name := site.Name
+		if name == "" {
+			name = site.WebsiteID
+		}
+		fmt.Fprintf(&b, "%4s %-32s failed: %s\n", "-", name, site.Error)
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchPortfolio_BoundedConcurrency(t *testing.T) {
+	websites := make([]Website, 12)
+	for i := range websites {
+		websites[i] = Website{ID: fmt.Sprintf("site-%d", i)}
+	}
+
+	var inFlight, peak atomic.Int32
+	sites := fetchPortfolio(websites, 3, func(w Website) (*StatsReport, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+
+		if w.ID == "site-4" {
+			return nil, errors.New("upstream timeout")
+		}
+		return &StatsReport{}, nil
+	})
+
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent fetches, saw %d", peak.Load())
+	}
+	for i, site := range sites {
+		if site.WebsiteID != websites[i].ID {
+			t.Errorf("Result %d out of order: %s", i, site.WebsiteID)
+		}
+	}
+	if sites[4].Error != "upstream timeout" || sites[4].Current != nil {
+		t.Errorf("Expected site-4 to record its error, got %+v", sites[4])
+	}
+}
+
+func TestRankPortfolio_Growth(t *testing.T) {
+	site := func(name string, visitors, previous int) PortfolioSite {
+		current := PeriodStats{Visitors: visitors}
+		prev := PeriodStats{Visitors: previous}
+		report := buildStatsReport(comparePreviousPeriod, current, prev)
+		return PortfolioSite{Name: name, Current: &report.Current, Previous: &report.Previous, Change: report.Change}
+	}
+
+	report := rankPortfolio([]PortfolioSite{
+		site("big", 1000, 900),
+		site("new", 50, 0),
+		site("rising", 200, 100),
+		{Name: "broken", Error: "API error 500"},
+	}, "visitors", portfolioSortGrowth)
+
+	var order []string
+	for _, s := range report.Sites {
+		order = append(order, s.Name)
+	}
+	if strings.Join(order, ",") != "rising,big,new" {
+		t.Errorf("Expected rising,big,new, got %v", order)
+	}
+	if report.Sites[0].Rank != 1 || report.Sites[2].Rank != 3 {
+		t.Errorf("Unexpected ranks: %+v", report.Sites)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Name != "broken" {
+		t.Errorf("Expected broken site in failed list, got %+v", report.Failed)
+	}
+}
+
+func TestMCPServer_GetPortfolioStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/websites":
+			_, _ = fmt.Fprint(w, `{"data": [{"id": "aaa111", "name": "Blog"}, {"id": "bbb222", "name": "Docs"},`+
+				`{"id": "ccc333", "name": "Shop"}]}`)
+		case "/api/websites/aaa111/stats":
+			_, _ = fmt.Fprint(w, `{"visitors": 100, "comparison": {"visitors": 50}}`)
+		case "/api/websites/bbb222/stats":
+			_, _ = fmt.Fprint(w, `{"visitors": 300, "comparison": {"visitors": 400}}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "get_portfolio_stats",
+		"arguments": map[string]any{
+			"start_date": "2025-02-01",
+			"end_date":   "2025-02-28",
+			"sort":       "change",
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	content := resp.Result.(map[string]any)["content"].([]map[string]string)
+	var report PortfolioReport
+	if err := json.Unmarshal([]byte(content[0]["text"]), &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+
+	if len(report.Sites) != 2 || report.Sites[0].Name != "Blog" || report.Sites[1].Name != "Docs" {
+		t.Errorf("Expected Blog ranked above Docs by change, got %+v", report.Sites)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Name != "Shop" {
+		t.Errorf("Expected Shop to fail on its own, got %+v", report.Failed)
+	}
+	if !strings.Contains(content[1]["text"], "Blog") {
+		t.Errorf("Expected table to list sites, got %q", content[1]["text"])
+	}
+}
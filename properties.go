@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"sort"
+)
+
+// eventDataTypes names Umami's numeric event data types.
+var eventDataTypes = map[int]string{
+	1: "string",
+	2: "number",
+	3: "boolean",
+	4: "date",
+	5: "array",
+}
+
+type EventPropertyValue struct {
+	Value      any           `json:"value"`
+	Total      int           `json:"total"`
+	Percentage float64       `json:"percentage"`
+	Series     []EventSeries `json:"series,omitempty"`
+}
+
+type EventProperty struct {
+	Name     string               `json:"name"`
+	DataType string               `json:"dataType"`
+	Total    int                  `json:"total"`
+	Values   []EventPropertyValue `json:"values"`
+	Note     string               `json:"note,omitempty"`
+}
+
+// seriesUnfilteredNote explains why per-value series were dropped.
+const seriesUnfilteredNote = "Per-value series omitted: this Umami instance does not filter " +
+	"the event series by property value, so every value would show the whole event's series."
+
+// eventPropertyKeys returns the property keys recorded for an event, most
+// used first. Umami lists a row per event and property, so rows belonging to
+// other events and rows without a property are skipped.
+func eventPropertyKeys(events []EventData, eventName string) []EventProperty {
+	var properties []EventProperty
+	for _, e := range events {
+		if e.EventName != eventName || e.PropertyName == "" {
+			continue
+		}
+		dataType, ok := eventDataTypes[e.DataType]
+		if !ok {
+			dataType = "unknown"
+		}
+		properties = append(properties, EventProperty{Name: e.PropertyName, DataType: dataType, Total: e.Total})
+	}
+
+	sort.SliceStable(properties, func(i, j int) bool {
+		return properties[i].Total > properties[j].Total
+	})
+
+	return properties
+}
+
+// eventPropertyValues ranks the values of a property by count and keeps the
+// top limit. Percentages are shares of all recorded values, not only the kept ones.
+func eventPropertyValues(values []EventValue, limit int) []EventPropertyValue {
+	total := 0
+	for _, v := range values {
+		total += v.Total
+	}
+
+	ranked := make([]EventPropertyValue, 0, len(values))
+	for _, v := range values {
+		ranked = append(ranked, EventPropertyValue{Value: v.Value, Total: v.Total, Percentage: percentage(v.Total, total)})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Total > ranked[j].Total
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
+
+// valueSeriesFiltered reports whether the series fetched for each value were
+// really narrowed to that value. Umami versions that ignore the property
+// filter return the whole event's series, which shows up as a series holding
+// more events than its value, or as identical series for values of
+// different size.
+func valueSeriesFiltered(values []EventPropertyValue) bool {
+	for i, v := range values {
+		total := 0
+		for _, point := range v.Series {
+			total += point.Y
+		}
+		if total > v.Total {
+			return false
+		}
+		for _, other := range values[:i] {
+			if other.Total != v.Total && len(v.Series) > 0 && slices.Equal(other.Series, v.Series) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type SessionDataProperty struct {
+	Name   string               `json:"name"`
+	Total  int                  `json:"total"`
+	Values []EventPropertyValue `json:"values"`
+}
+
+// UserSession is one session of an identified user together with what
+// happened in it.
+type UserSession struct {
+	Session
+	Activity   []SessionActivity `json:"activity"`
+	Properties []SessionProperty `json:"properties,omitempty"`
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventPropertyKeys(t *testing.T) {
+	events := []EventData{
+		{EventName: "signup", PropertyName: "plan", DataType: 1, Total: 40},
+		{EventName: "signup", PropertyName: "variant", DataType: 1, Total: 90},
+		{EventName: "signup", PropertyName: "", Total: 130},
+		{EventName: "checkout", PropertyName: "amount", DataType: 2, Total: 12},
+	}
+
+	keys := eventPropertyKeys(events, "signup")
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 property keys, got %+v", keys)
+	}
+	if keys[0].Name != "variant" || keys[1].Name != "plan" || keys[0].DataType != "string" {
+		t.Errorf("Expected keys ranked by total, got %+v", keys)
+	}
+}
+
+func TestEventPropertyValues(t *testing.T) {
+	values := []EventValue{
+		{Value: "a", Total: 25},
+		{Value: "b", Total: 50},
+		{Value: "c", Total: 25},
+	}
+
+	ranked := eventPropertyValues(values, 2)
+	if len(ranked) != 2 {
+		t.Fatalf("Expected 2 values, got %d", len(ranked))
+	}
+	if ranked[0].Value != "b" || ranked[0].Percentage != 50 || ranked[1].Percentage != 25 {
+		t.Errorf("Unexpected ranking: %+v", ranked)
+	}
+}
+
+func TestMCPServer_GetEventProperties(t *testing.T) {
+	seriesCalls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/websites/abc123/event-data/events":
+			_, _ = w.Write([]byte(`[{"eventName": "signup", "propertyName": "variant", "dataType": 1, "total": 30}]`))
+		case "/api/websites/abc123/event-data/values":
+			if r.URL.Query().Get("propertyName") != "variant" {
+				t.Errorf("Expected propertyName=variant, got %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"value": "A", "total": 10}, {"value": "B", "total": 20}]`))
+		case "/api/websites/abc123/events/series":
+			seriesCalls++
+			counts := map[string]int{"A": 10, "B": 20}
+			_, _ = fmt.Fprintf(w, `[{"x": "signup", "t": "2025-01-01", "y": %d}]`, counts[r.URL.Query().Get("propertyValue")])
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "get_event_properties",
+		"arguments": map[string]any{
+			"website_id":     "abc123",
+			"start_date":     "2025-01-01",
+			"end_date":       "2025-01-31",
+			"event_name":     "signup",
+			"include_series": true,
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	text := resp.Result.(map[string]any)["content"].([]map[string]string)[0]["text"]
+	var result struct {
+		Properties []EventProperty `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+
+	if len(result.Properties) != 1 || len(result.Properties[0].Values) != 2 {
+		t.Fatalf("Unexpected properties: %+v", result.Properties)
+	}
+	if top := result.Properties[0].Values[0]; top.Value != "B" || len(top.Series) != 1 {
+		t.Errorf("Expected B first with a series, got %+v", top)
+	}
+	if seriesCalls != 2 {
+		t.Errorf("Expected one series request per value, got %d", seriesCalls)
+	}
+}
+
+func TestMCPServer_GetEventPropertiesDropsUnfilteredSeries(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/websites/abc123/event-data/values":
+			_, _ = w.Write([]byte(`[{"value": "A", "total": 10}, {"value": "B", "total": 20}]`))
+		case "/api/websites/abc123/events/series":
+			// The property filter is ignored, so every value gets the whole event
+			_, _ = w.Write([]byte(`[{"x": "signup", "t": "2025-01-01", "y": 30}]`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer ts.Close()
+
+	server := &MCPServer{client: &UmamiClient{baseURL: ts.URL, token: "test-token", httpClient: &http.Client{}}}
+
+	params, _ := json.Marshal(map[string]any{
+		"name": "get_event_properties",
+		"arguments": map[string]any{
+			"website_id":     "abc123",
+			"event_name":     "signup",
+			"property_name":  "variant",
+			"include_series": true,
+		},
+	})
+	resp := server.HandleRequest(Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %v", resp.Error)
+	}
+
+	text := resp.Result.(map[string]any)["content"].([]map[string]string)[0]["text"]
+	var result struct {
+		Properties []EventProperty `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+
+	property := result.Properties[0]
+	if property.Note == "" {
+		t.Error("Expected a note explaining the missing series")
+	}
+	for _, v := range property.Values {
+		if v.Series != nil {
+			t.Errorf("Expected no series for %v, got %+v", v.Value, v.Series)
+		}
+	}
+}
+
+func TestValueSeriesFiltered(t *testing.T) {
+	day := func(y int) []EventSeries { return []EventSeries{{X: "signup", T: "2025-01-01", Y: y}} }
+
+	tests := []struct {
+		name   string
+		values []EventPropertyValue
+		want   bool
+	}{
+		{"per value", []EventPropertyValue{{Total: 20, Series: day(20)}, {Total: 10, Series: day(10)}}, true},
+		{"equal totals", []EventPropertyValue{{Total: 10, Series: day(10)}, {Total: 10, Series: day(10)}}, true},
+		{"identical", []EventPropertyValue{{Total: 20, Series: day(5)}, {Total: 10, Series: day(5)}}, false},
+		{"whole event", []EventPropertyValue{{Total: 10, Series: day(30)}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := valueSeriesFiltered(tt.values); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+// supportedProtocolVersions lists the MCP revisions this server speaks, newest first.
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// defaultProtocolVersion answers clients that don't state a version, which
+// predate negotiation and expect the revision the server originally spoke.
+const defaultProtocolVersion = "2025-03-26"
+
+// structuredOutputVersion is the first revision with outputSchema and structuredContent.
+const structuredOutputVersion = "2025-06-18"
+
+// lifecycleState tracks where a connection is in the MCP lifecycle. Over
+// HTTP a session starts out ready once initialize succeeds.
+type lifecycleState int32
+
+const (
+	stateUninitialized lifecycleState = iota
+	stateInitializing
+	stateReady
+)
+
+var protocolVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// negotiateProtocolVersion picks the newest supported revision that is not
+// newer than the one the client requested. Revisions are dates, so they
+// compare as strings. It returns false when the client is older than every
+// supported revision or sent something that is not a revision.
+func negotiateProtocolVersion(requested string) (string, bool) {
+	if requested == "" {
+		return defaultProtocolVersion, true
+	}
+	if !protocolVersionPattern.MatchString(requested) {
+		return "", false
+	}
+	for _, v := range supportedProtocolVersions {
+		if v <= requested {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// supportsStructuredOutput reports whether the negotiated revision lets tool
+// results carry structuredContent.
+func (s *MCPServer) supportsStructuredOutput() bool {
+	return s.protocolVersion >= structuredOutputVersion
+}
+
+// admit rejects requests that arrive out of lifecycle order. Until the client
+// sends notifications/initialized only initialize, ping and logging/setLevel
+// are served, and initialize is accepted once.
+func (s *MCPServer) admit(method string) *Error {
+	switch lifecycleState(s.state.Load()) {
+	case stateUninitialized:
+		if method == "initialize" || method == "ping" {
+			return nil
+		}
+		return &Error{Code: -32600, Message: "Server not initialized"}
+	case stateInitializing:
+		switch method {
+		case "ping", "logging/setLevel":
+			return nil
+		case "initialize":
+			return &Error{Code: -32600, Message: "Server already initialized"}
+		}
+		return &Error{Code: -32600, Message: "Server not initialized: waiting for notifications/initialized"}
+	case stateReady:
+		if method == "initialize" {
+			return &Error{Code: -32600, Message: "Server already initialized"}
+		}
+	}
+	return nil
+}
+
+func (s *MCPServer) processInitialize(rawParams json.RawMessage) (any, *Error) {
+	var params struct {
+		ProtocolVersion string                     `json:"protocolVersion"`
+		Capabilities    map[string]json.RawMessage `json:"capabilities"`
+	}
+
+	if len(rawParams) > 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, &Error{Code: -32602, Message: "Invalid params"}
+		}
+	}
+
+	negotiated, ok := negotiateProtocolVersion(params.ProtocolVersion)
+	if !ok {
+		return nil, &Error{
+			Code:    -32602,
+			Message: "Unsupported protocol version",
+			Data: map[string]any{
+				"supported": supportedProtocolVersions,
+				"requested": params.ProtocolVersion,
+			},
+		}
+	}
+
+	s.protocolVersion = negotiated
+	s.clientCapabilities = params.Capabilities
+
+	return map[string]any{
+		"protocolVersion": negotiated,
+		"serverInfo": map[string]string{
+			"name":    "umami-mcp",
+			"version": version,
+		},
+		"capabilities": map[string]any{
+			"tools":     map[string]any{},
+			"prompts":   map[string]any{},
+			"resources": map[string]any{},
+			"logging":   map[string]any{},
+		},
+	}, nil
+}
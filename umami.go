@@ -3,10 +3,14 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -22,6 +26,18 @@ type UmamiClient struct {
 	token       string
 	teamID      string
 	httpClient  *http.Client
+
+	// logger receives diagnostics such as slow calls and upstream errors.
+	logger func(level, message string)
+}
+
+// slowRequestThreshold is how long an Umami call may take before it is reported.
+const slowRequestThreshold = 5 * time.Second
+
+func (c *UmamiClient) log(level, format string, args ...any) {
+	if c.logger != nil {
+		c.logger(level, fmt.Sprintf(format, args...))
+	}
 }
 
 func NewUmamiClient(baseURL, username, password string) *UmamiClient {
@@ -96,12 +112,66 @@ func (c *UmamiClient) Authenticate() error {
 	return nil
 }
 func (c *UmamiClient) doRequest(path string, params map[string]string) ([]byte, error) {
+	return c.send(http.MethodGet, path, params, nil)
+}
+
+func (c *UmamiClient) doPost(path string, payload any) ([]byte, error) {
+	return c.send(http.MethodPost, path, nil, payload)
+}
+
+func (c *UmamiClient) doDelete(path string) ([]byte, error) {
+	return c.send(http.MethodDelete, path, nil, nil)
+}
+
+func (c *UmamiClient) send(method, path string, params map[string]string, payload any) ([]byte, error) {
+	return c.sendLogged("error", method, path, params, payload)
+}
+
+// probe is doRequest for optional endpoints that some Umami instances lack.
+// Callers fall back when it fails, so failures are only logged at debug.
+func (c *UmamiClient) probe(path string, params map[string]string) ([]byte, error) {
+	return c.sendLogged("debug", http.MethodGet, path, params, nil)
+}
+
+// sendLogged performs a request and reports upstream failures at failureLevel.
+func (c *UmamiClient) sendLogged(
+	failureLevel, method, path string, params map[string]string, payload any,
+) ([]byte, error) {
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	body, status, err := c.do(method, path, params, data)
+	if err != nil {
+		c.log(failureLevel, "Umami %s %s failed: %v", method, path, err)
+		return nil, err
+	}
+
+	if status >= 400 {
+		c.log(failureLevel, "Umami %s %s returned status %d", method, path, status)
+		return nil, fmt.Errorf("API error %d: %s", status, string(body))
+	}
+
+	return body, nil
+}
+
+// do performs one request and returns the body and status code.
+func (c *UmamiClient) do(method, path string, params map[string]string, payload []byte) ([]byte, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, http.NoBody)
+	var reqBody io.Reader = http.NoBody
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if params != nil {
@@ -119,45 +189,148 @@ func (c *UmamiClient) doRequest(path string, params map[string]string) ([]byte,
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	if elapsed := time.Since(start); elapsed > slowRequestThreshold {
+		c.log("warning", "Umami %s %s took %s", method, path, elapsed.Round(time.Millisecond))
 	}
 
-	return body, nil
+	return body, resp.StatusCode, nil
+}
+
+// applyFilters copies validated tool filters into the query parameters
+// without overriding the parameters the endpoint itself relies on.
+func applyFilters(params, filters map[string]string) {
+	for k, v := range filters {
+		if _, exists := params[k]; !exists {
+			params[k] = v
+		}
+	}
 }
 
 type Website struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
 	Domain    string    `json:"domain"`
+	ShareID   string    `json:"shareId,omitempty"`
+	TeamID    string    `json:"teamId,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
 func (c *UmamiClient) GetWebsites(includeTeams bool) ([]Website, error) {
-	var endpoint string
+	if c.teamID != "" {
+		return c.GetTeamWebsites(c.teamID)
+	}
+
 	var params map[string]string
+	if includeTeams {
+		params = map[string]string{"includeTeams": "true"}
+	}
 
-	if c.teamID != "" {
-		endpoint = fmt.Sprintf("%s/teams/%s/websites", c.basePath(), c.teamID)
-	} else {
-		endpoint = c.websitesPath()
-		if includeTeams {
-			params = map[string]string{"includeTeams": "true"}
-		}
+	data, err := c.doRequest(c.websitesPath(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []Website `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+type Team struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Members   int       `json:"members"`
+	Websites  int       `json:"websites"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetTeams lists the teams the authenticated user belongs to.
+func (c *UmamiClient) GetTeams() ([]Team, error) {
+	data, err := c.doRequest(c.basePath()+"/me/teams", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			Name      string    `json:"name"`
+			CreatedAt time.Time `json:"createdAt"`
+			Count     struct {
+				Website  int `json:"website"`
+				TeamUser int `json:"teamUser"`
+			} `json:"_count"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	teams := make([]Team, 0, len(result.Data))
+	for _, t := range result.Data {
+		teams = append(teams, Team{
+			ID:        t.ID,
+			Name:      t.Name,
+			Members:   t.Count.TeamUser,
+			Websites:  t.Count.Website,
+			CreatedAt: t.CreatedAt,
+		})
+	}
+
+	return teams, nil
+}
+
+type TeamMember struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (c *UmamiClient) GetTeamMembers(teamID string) ([]TeamMember, error) {
+	data, err := c.doRequest(fmt.Sprintf("%s/teams/%s/users", c.basePath(), teamID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			UserID string `json:"userId"`
+			Role   string `json:"role"`
+			User   struct {
+				Username string `json:"username"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
 	}
 
-	data, err := c.doRequest(endpoint, params)
+	members := make([]TeamMember, 0, len(result.Data))
+	for _, m := range result.Data {
+		members = append(members, TeamMember{UserID: m.UserID, Username: m.User.Username, Role: m.Role})
+	}
+
+	return members, nil
+}
+
+func (c *UmamiClient) GetTeamWebsites(teamID string) ([]Website, error) {
+	data, err := c.doRequest(fmt.Sprintf("%s/teams/%s/websites", c.basePath(), teamID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -172,6 +345,145 @@ func (c *UmamiClient) GetWebsites(includeTeams bool) ([]Website, error) {
 	return result.Data, nil
 }
 
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetUsers lists every user on the instance. Umami only allows this for admins.
+func (c *UmamiClient) GetUsers() ([]User, error) {
+	data, err := c.doRequest(c.basePath()+"/admin/users", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []User `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+func (c *UmamiClient) CreateWebsite(name, domain string) (*Website, error) {
+	payload := map[string]string{
+		"name":   name,
+		"domain": domain,
+	}
+	if c.teamID != "" {
+		payload["teamId"] = c.teamID
+	}
+
+	data, err := c.doPost(c.websitesPath(), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var website Website
+	if err := json.Unmarshal(data, &website); err != nil {
+		return nil, err
+	}
+
+	return &website, nil
+}
+
+func (c *UmamiClient) UpdateWebsite(websiteID, name, domain string) (*Website, error) {
+	payload := map[string]string{}
+	if name != "" {
+		payload["name"] = name
+	}
+	if domain != "" {
+		payload["domain"] = domain
+	}
+
+	data, err := c.doPost(fmt.Sprintf("%s/%s", c.websitesPath(), websiteID), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var website Website
+	if err := json.Unmarshal(data, &website); err != nil {
+		return nil, err
+	}
+
+	return &website, nil
+}
+
+func (c *UmamiClient) GetWebsite(websiteID string) (*Website, error) {
+	data, err := c.doRequest(fmt.Sprintf("%s/%s", c.websitesPath(), websiteID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var website Website
+	if err := json.Unmarshal(data, &website); err != nil {
+		return nil, err
+	}
+
+	return &website, nil
+}
+
+// SetShareID replaces the public share ID of a website. An empty shareID
+// disables sharing.
+func (c *UmamiClient) SetShareID(websiteID, shareID string) (*Website, error) {
+	payload := map[string]any{"shareId": nil}
+	if shareID != "" {
+		payload["shareId"] = shareID
+	}
+
+	data, err := c.doPost(fmt.Sprintf("%s/%s", c.websitesPath(), websiteID), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var website Website
+	if err := json.Unmarshal(data, &website); err != nil {
+		return nil, err
+	}
+
+	return &website, nil
+}
+
+// ShareURL is the public dashboard address for a share ID.
+func (c *UmamiClient) ShareURL(shareID string) string {
+	return fmt.Sprintf("%s/share/%s", c.baseURL, shareID)
+}
+
+const shareIDChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// generateShareID returns a random 16 character ID, the format Umami itself uses.
+// Bytes at or above the largest multiple of len(shareIDChars) are discarded so
+// every character is equally likely.
+func generateShareID() string {
+	const limit = 256 - 256%len(shareIDChars)
+
+	id := make([]byte, 0, 16)
+	buf := make([]byte, 32)
+	for len(id) < cap(id) {
+		_, _ = rand.Read(buf)
+		for _, b := range buf {
+			if int(b) < limit && len(id) < cap(id) {
+				id = append(id, shareIDChars[int(b)%len(shareIDChars)])
+			}
+		}
+	}
+	return string(id)
+}
+
+func (c *UmamiClient) ResetWebsite(websiteID string) error {
+	_, err := c.doPost(fmt.Sprintf("%s/%s/reset", c.websitesPath(), websiteID), map[string]string{})
+	return err
+}
+
+func (c *UmamiClient) DeleteWebsite(websiteID string) error {
+	_, err := c.doDelete(fmt.Sprintf("%s/%s", c.websitesPath(), websiteID))
+	return err
+}
+
 type Stats struct {
 	PageViews  int              `json:"pageviews"`
 	Visitors   int              `json:"visitors"`
@@ -189,11 +501,59 @@ type StatsComparison struct {
 	TotalTime int `json:"totaltime"`
 }
 
-func (c *UmamiClient) GetStats(websiteID, startDate, endDate string) (*Stats, error) {
+// totals returns the stats without the comparison period.
+func (s *Stats) totals() StatsComparison {
+	return StatsComparison{
+		PageViews: s.PageViews,
+		Visitors:  s.Visitors,
+		Visits:    s.Visits,
+		Bounces:   s.Bounces,
+		TotalTime: s.TotalTime,
+	}
+}
+
+type Revenue struct {
+	Currency  string             `json:"currency"`
+	Total     RevenueTotal       `json:"total"`
+	Series    []RevenuePoint     `json:"series"`
+	Countries []RevenueBreakdown `json:"countries"`
+	Referrers []RevenueBreakdown `json:"referrers"`
+}
+
+type RevenueTotal struct {
+	Sum         float64 `json:"sum"`
+	Count       int     `json:"count"`
+	UniqueCount int     `json:"unique_count"`
+	Average     float64 `json:"average"`
+}
+
+type RevenuePoint struct {
+	T string  `json:"t"`
+	Y float64 `json:"y"`
+}
+
+type RevenueBreakdown struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func (c *UmamiClient) GetStats(websiteID, startDate, endDate string, filters map[string]string) (*Stats, error) {
+	return c.GetStatsCompared(websiteID, startDate, endDate, "", filters)
+}
+
+// GetStatsCompared asks Umami to include a comparison period ("prev" or "yoy").
+// Older Umami versions ignore the parameter and return no comparison.
+func (c *UmamiClient) GetStatsCompared(
+	websiteID, startDate, endDate, compare string, filters map[string]string,
+) (*Stats, error) {
 	params := map[string]string{
 		"startAt": startDate,
 		"endAt":   endDate,
 	}
+	if compare != "" {
+		params["compare"] = compare
+	}
+	applyFilters(params, filters)
 
 	data, err := c.doRequest(fmt.Sprintf("%s/%s/stats", c.websitesPath(), websiteID), params)
 	if err != nil {
@@ -208,17 +568,82 @@ func (c *UmamiClient) GetStats(websiteID, startDate, endDate string) (*Stats, er
 	return &stats, nil
 }
 
+func (c *UmamiClient) GetRevenue(
+	websiteID, startDate, endDate, unit, timezone, currency string, filters map[string]string,
+) (*Revenue, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"unit":      unit,
+		"timezone":  timezone,
+		"currency":  currency,
+	}
+
+	data, err := c.runReport("revenue", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Chart []struct {
+			T string  `json:"t"`
+			Y float64 `json:"y"`
+		} `json:"chart"`
+		Country  []RevenueBreakdown `json:"country"`
+		Referrer []RevenueBreakdown `json:"referrer"`
+		Total    RevenueTotal       `json:"total"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	// The chart is split per revenue event; sum it into a single series
+	var series []RevenuePoint
+	index := make(map[string]int)
+	for _, point := range response.Chart {
+		if i, ok := index[point.T]; ok {
+			series[i].Y += point.Y
+			continue
+		}
+		index[point.T] = len(series)
+		series = append(series, RevenuePoint{T: point.T, Y: point.Y})
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].T < series[j].T
+	})
+
+	return &Revenue{
+		Currency:  currency,
+		Total:     response.Total,
+		Series:    series,
+		Countries: response.Country,
+		Referrers: response.Referrer,
+	}, nil
+}
+
 type PageView struct {
 	T string `json:"t"`
+	X string `json:"x,omitempty"`
 	Y int    `json:"y"`
 }
 
-func (c *UmamiClient) GetPageViews(websiteID, startDate, endDate, unit string) ([]PageView, error) {
+// time returns the bucket label, which Umami sends as x or t depending on version.
+func (p PageView) time() string {
+	if p.T != "" {
+		return p.T
+	}
+	return p.X
+}
+
+func (c *UmamiClient) GetPageViews(
+	websiteID, startDate, endDate, unit string, filters map[string]string,
+) ([]PageView, error) {
 	params := map[string]string{
 		"startAt": startDate,
 		"endAt":   endDate,
 		"unit":    unit,
 	}
+	applyFilters(params, filters)
 
 	data, err := c.doRequest(fmt.Sprintf("%s/%s/pageviews", c.websitesPath(), websiteID), params)
 	if err != nil {
@@ -240,12 +665,82 @@ func (c *UmamiClient) GetPageViews(websiteID, startDate, endDate, unit string) (
 	return response.PageViews, nil
 }
 
+type TrafficSeries struct {
+	PageViews []PageView `json:"pageviews"`
+	Sessions  []PageView `json:"sessions"`
+}
+
+// GetTrafficSeries returns both the pageview and the visitor series, bucketed
+// by unit in the given timezone.
+func (c *UmamiClient) GetTrafficSeries(
+	websiteID, startDate, endDate, unit, timezone string, filters map[string]string,
+) (*TrafficSeries, error) {
+	params := map[string]string{
+		"startAt":  startDate,
+		"endAt":    endDate,
+		"unit":     unit,
+		"timezone": timezone,
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/pageviews", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var series TrafficSeries
+	if err := json.Unmarshal(data, &series); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// maxHourlyRange is the longest range Umami will bucket by hour. Longer
+// ranges are silently returned by day instead.
+const maxHourlyRange = 48 * time.Hour
+
+// GetHourlyTrafficSeries returns the hourly traffic series for any range by
+// fetching it in windows of at most maxHourlyRange and concatenating them.
+// startDate and endDate are Unix milliseconds, as produced by normalizeDate.
+func (c *UmamiClient) GetHourlyTrafficSeries(
+	websiteID, startDate, endDate, timezone string, filters map[string]string,
+) (*TrafficSeries, error) {
+	start, errStart := strconv.ParseInt(startDate, 10, 64)
+	end, errEnd := strconv.ParseInt(endDate, 10, 64)
+	if errStart != nil || errEnd != nil {
+		return c.GetTrafficSeries(websiteID, startDate, endDate, "hour", timezone, filters)
+	}
+
+	var merged TrafficSeries
+	for windowStart := start; ; {
+		windowEnd := min(windowStart+maxHourlyRange.Milliseconds(), end)
+		series, err := c.GetTrafficSeries(
+			websiteID, strconv.FormatInt(windowStart, 10), strconv.FormatInt(windowEnd, 10), "hour", timezone, filters,
+		)
+		if err != nil {
+			return nil, err
+		}
+		merged.PageViews = append(merged.PageViews, series.PageViews...)
+		merged.Sessions = append(merged.Sessions, series.Sessions...)
+
+		if windowEnd >= end {
+			break
+		}
+		windowStart = windowEnd + 1
+	}
+
+	return &merged, nil
+}
+
 type Metric struct {
 	X string `json:"x"`
 	Y int    `json:"y"`
 }
 
-func (c *UmamiClient) GetMetrics(websiteID, startDate, endDate, metricType string, limit int) ([]Metric, error) {
+func (c *UmamiClient) GetMetrics(
+	websiteID, startDate, endDate, metricType string, limit int, filters map[string]string,
+) ([]Metric, error) {
 	// Map legacy "url" type to current "path" type (renamed Oct 2025)
 	if metricType == "url" {
 		metricType = metricTypePath
@@ -257,6 +752,7 @@ func (c *UmamiClient) GetMetrics(websiteID, startDate, endDate, metricType strin
 		"type":    metricType,
 		"limit":   fmt.Sprintf("%d", limit),
 	}
+	applyFilters(params, filters)
 
 	data, err := c.doRequest(fmt.Sprintf("%s/%s/metrics", c.websitesPath(), websiteID), params)
 	if err != nil {
@@ -271,6 +767,21 @@ func (c *UmamiClient) GetMetrics(websiteID, startDate, endDate, metricType strin
 	return metrics, nil
 }
 
+// sortedMetrics converts a value -> count map into metrics ordered by count.
+func sortedMetrics(counts map[string]int) []Metric {
+	metrics := make([]Metric, 0, len(counts))
+	for value, count := range counts {
+		metrics = append(metrics, Metric{X: value, Y: count})
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Y != metrics[j].Y {
+			return metrics[i].Y > metrics[j].Y
+		}
+		return metrics[i].X < metrics[j].X
+	})
+	return metrics
+}
+
 func (c *UmamiClient) GetActive(websiteID string) ([]Metric, error) {
 	data, err := c.doRequest(fmt.Sprintf("%s/%s/active", c.websitesPath(), websiteID), nil)
 	if err != nil {
@@ -297,3 +808,733 @@ func (c *UmamiClient) GetActive(websiteID string) ([]Metric, error) {
 	}
 	return metrics, nil
 }
+
+type Realtime struct {
+	ActiveVisitors int             `json:"activeVisitors"`
+	Totals         RealtimeTotals  `json:"totals"`
+	Pages          []Metric        `json:"pages"`
+	Referrers      []Metric        `json:"referrers"`
+	Countries      []Metric        `json:"countries"`
+	Events         []RealtimeEvent `json:"events"`
+}
+
+type RealtimeTotals struct {
+	Views     int `json:"views"`
+	Visitors  int `json:"visitors"`
+	Events    int `json:"events"`
+	Countries int `json:"countries"`
+}
+
+type RealtimeEvent struct {
+	Type           string    `json:"type"`
+	CreatedAt      time.Time `json:"createdAt"`
+	URLPath        string    `json:"urlPath,omitempty"`
+	EventName      string    `json:"eventName,omitempty"`
+	ReferrerDomain string    `json:"referrerDomain,omitempty"`
+	Country        string    `json:"country,omitempty"`
+	Browser        string    `json:"browser,omitempty"`
+	Device         string    `json:"device,omitempty"`
+	SessionID      string    `json:"sessionId,omitempty"`
+}
+
+func (c *UmamiClient) GetRealtime(websiteID string, since time.Time) (*Realtime, error) {
+	params := map[string]string{
+		"startAt": fmt.Sprintf("%d", since.UnixMilli()),
+	}
+
+	data, err := c.doRequest(fmt.Sprintf("%s/realtime/%s", c.basePath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Countries map[string]int `json:"countries"`
+		URLs      map[string]int `json:"urls"`
+		Referrers map[string]int `json:"referrers"`
+		Events    []struct {
+			RealtimeEvent
+			Type string `json:"__type"`
+		} `json:"events"`
+		Totals RealtimeTotals `json:"totals"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	events := make([]RealtimeEvent, len(response.Events))
+	for i, e := range response.Events {
+		events[i] = e.RealtimeEvent
+		events[i].Type = e.Type
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	return &Realtime{
+		Totals:    response.Totals,
+		Pages:     sortedMetrics(response.URLs),
+		Referrers: sortedMetrics(response.Referrers),
+		Countries: sortedMetrics(response.Countries),
+		Events:    events,
+	}, nil
+}
+
+type EventData struct {
+	EventName    string `json:"eventName"`
+	PropertyName string `json:"propertyName"`
+	DataType     int    `json:"dataType"`
+	Total        int    `json:"total"`
+}
+
+func (c *UmamiClient) GetEvents(
+	websiteID, startDate, endDate, eventName string, filters map[string]string,
+) ([]EventData, error) {
+	params := map[string]string{
+		"startAt": startDate,
+		"endAt":   endDate,
+	}
+	applyFilters(params, filters)
+	if eventName != "" {
+		params["event"] = eventName
+	}
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/event-data/events", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []EventData
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+type EventSeries struct {
+	X string `json:"x"`
+	T string `json:"t"`
+	Y int    `json:"y"`
+}
+
+func (c *UmamiClient) GetEventSeries(
+	websiteID, startDate, endDate, unit, eventName string, filters map[string]string,
+) ([]EventSeries, error) {
+	params := map[string]string{
+		"startAt": startDate,
+		"endAt":   endDate,
+		"unit":    unit,
+	}
+	applyFilters(params, filters)
+	if eventName != "" {
+		params["event"] = eventName
+	}
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/events/series", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var series []EventSeries
+	if err := json.Unmarshal(data, &series); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+type EventValue struct {
+	Value any `json:"value"`
+	Total int `json:"total"`
+}
+
+func (c *UmamiClient) GetEventValues(
+	websiteID, startDate, endDate, eventName, propertyName string, filters map[string]string,
+) ([]EventValue, error) {
+	params := map[string]string{
+		"startAt":      startDate,
+		"endAt":        endDate,
+		"event":        eventName,
+		"propertyName": propertyName,
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/event-data/values", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []EventValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetEventValueSeries returns the series of one event restricted to events
+// whose property has the given value.
+func (c *UmamiClient) GetEventValueSeries(
+	websiteID, startDate, endDate, unit, eventName, propertyName, value string, filters map[string]string,
+) ([]EventSeries, error) {
+	params := map[string]string{
+		"startAt":       startDate,
+		"endAt":         endDate,
+		"unit":          unit,
+		"event":         eventName,
+		"propertyName":  propertyName,
+		"propertyValue": value,
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/events/series", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var series []EventSeries
+	if err := json.Unmarshal(data, &series); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	WebsiteID  string    `json:"websiteId"`
+	Hostname   string    `json:"hostname"`
+	Browser    string    `json:"browser"`
+	OS         string    `json:"os"`
+	Device     string    `json:"device"`
+	Screen     string    `json:"screen"`
+	Language   string    `json:"language"`
+	Country    string    `json:"country"`
+	Region     string    `json:"region"`
+	City       string    `json:"city"`
+	FirstAt    time.Time `json:"firstAt"`
+	LastAt     time.Time `json:"lastAt"`
+	Visits     int       `json:"visits"`
+	Views      int       `json:"views"`
+	DistinctID string    `json:"distinctId,omitempty"`
+}
+
+type SessionList struct {
+	Data     []Session `json:"data"`
+	Count    int       `json:"count"`
+	Page     int       `json:"page"`
+	PageSize int       `json:"pageSize"`
+}
+
+func (c *UmamiClient) GetSessions(
+	websiteID, startDate, endDate string, page, pageSize int, filters map[string]string,
+) (*SessionList, error) {
+	params := map[string]string{
+		"startAt":  startDate,
+		"endAt":    endDate,
+		"page":     fmt.Sprintf("%d", page),
+		"pageSize": fmt.Sprintf("%d", pageSize),
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/sessions", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions SessionList
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, err
+	}
+
+	return &sessions, nil
+}
+
+type SessionActivity struct {
+	CreatedAt      time.Time `json:"createdAt"`
+	URLPath        string    `json:"urlPath"`
+	URLQuery       string    `json:"urlQuery"`
+	ReferrerDomain string    `json:"referrerDomain"`
+	EventID        string    `json:"eventId"`
+	EventType      int       `json:"eventType"`
+	EventName      string    `json:"eventName"`
+	VisitID        string    `json:"visitId"`
+}
+
+func (c *UmamiClient) GetSessionActivity(websiteID, sessionID, startDate, endDate string) ([]SessionActivity, error) {
+	params := map[string]string{
+		"startAt": startDate,
+		"endAt":   endDate,
+	}
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/sessions/%s/activity", c.websitesPath(), websiteID, sessionID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var activity []SessionActivity
+	if err := json.Unmarshal(data, &activity); err != nil {
+		return nil, err
+	}
+
+	// Umami returns newest first; callers want the session replayed in order
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].CreatedAt.Before(activity[j].CreatedAt)
+	})
+
+	return activity, nil
+}
+
+type SessionProperty struct {
+	DataKey     string   `json:"dataKey"`
+	DataType    int      `json:"dataType"`
+	StringValue *string  `json:"stringValue,omitempty"`
+	NumberValue *float64 `json:"numberValue,omitempty"`
+	DateValue   *string  `json:"dateValue,omitempty"`
+}
+
+func (c *UmamiClient) GetSessionProperties(websiteID, sessionID string) ([]SessionProperty, error) {
+	data, err := c.probe(fmt.Sprintf("%s/%s/sessions/%s/properties", c.websitesPath(), websiteID, sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var properties []SessionProperty
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+type SessionDataKey struct {
+	PropertyName string `json:"propertyName"`
+	Total        int    `json:"total"`
+}
+
+// GetSessionDataProperties lists the session data keys recorded in the range.
+func (c *UmamiClient) GetSessionDataProperties(
+	websiteID, startDate, endDate string, filters map[string]string,
+) ([]SessionDataKey, error) {
+	params := map[string]string{
+		"startAt": startDate,
+		"endAt":   endDate,
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/session-data/properties", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []SessionDataKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (c *UmamiClient) GetSessionDataValues(
+	websiteID, startDate, endDate, propertyName string, filters map[string]string,
+) ([]EventValue, error) {
+	params := map[string]string{
+		"startAt":      startDate,
+		"endAt":        endDate,
+		"propertyName": propertyName,
+	}
+	applyFilters(params, filters)
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/session-data/values", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []EventValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetSessionsByDistinctID returns the sessions identified with distinctID.
+// Only sessions that report the same distinct ID are kept, so an instance
+// that ignores the filter never leaks another visitor's sessions.
+func (c *UmamiClient) GetSessionsByDistinctID(
+	websiteID, distinctID, startDate, endDate string, limit int,
+) ([]Session, error) {
+	list, err := c.GetSessions(websiteID, startDate, endDate, 1, limit, map[string]string{"distinctId": distinctID})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(list.Data))
+	for _, session := range list.Data {
+		if session.DistinctID == distinctID {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions, nil
+}
+
+// Segment is a saved set of filters. Umami stores cohorts the same way with
+// type "cohort".
+type Segment struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+func (c *UmamiClient) GetSegments(websiteID, segmentType string) ([]Segment, error) {
+	var params map[string]string
+	if segmentType != "" {
+		params = map[string]string{"type": segmentType}
+	}
+
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/segments", c.websitesPath(), websiteID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []Segment `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+func (c *UmamiClient) GetSegment(websiteID, segmentID string) (*Segment, error) {
+	data, err := c.doRequest(fmt.Sprintf("%s/%s/segments/%s", c.websitesPath(), websiteID, segmentID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var segment Segment
+	if err := json.Unmarshal(data, &segment); err != nil {
+		return nil, err
+	}
+
+	return &segment, nil
+}
+
+type reportRequest struct {
+	WebsiteID  string            `json:"websiteId"`
+	Type       string            `json:"type"`
+	Filters    map[string]string `json:"filters"`
+	Parameters any               `json:"parameters"`
+}
+
+func (c *UmamiClient) runReport(
+	reportType, websiteID string, filters map[string]string, parameters any,
+) ([]byte, error) {
+	if filters == nil {
+		filters = map[string]string{}
+	}
+
+	return c.doPost(fmt.Sprintf("%s/reports/%s", c.basePath(), reportType), reportRequest{
+		WebsiteID:  websiteID,
+		Type:       reportType,
+		Filters:    filters,
+		Parameters: parameters,
+	})
+}
+
+// percentage returns part/total as a percentage rounded to two decimals.
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+type FunnelStep struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type FunnelStepResult struct {
+	Type           string  `json:"type"`
+	Value          string  `json:"value"`
+	Visitors       int     `json:"visitors"`
+	Dropped        int     `json:"dropped"`
+	Conversion     float64 `json:"conversion"`
+	StepConversion float64 `json:"stepConversion"`
+}
+
+func (c *UmamiClient) GetFunnel(
+	websiteID, startDate, endDate string, steps []FunnelStep, window int, filters map[string]string,
+) ([]FunnelStepResult, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"window":    window,
+		"steps":     steps,
+	}
+
+	data, err := c.runReport("funnel", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []struct {
+		Type     string `json:"type"`
+		Value    string `json:"value"`
+		Visitors int    `json:"visitors"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	results := make([]FunnelStepResult, len(response))
+	for i, r := range response {
+		previous := r.Visitors
+		if i > 0 {
+			previous = response[i-1].Visitors
+		}
+		results[i] = FunnelStepResult{
+			Type:           r.Type,
+			Value:          r.Value,
+			Visitors:       r.Visitors,
+			Dropped:        previous - r.Visitors,
+			Conversion:     percentage(r.Visitors, response[0].Visitors),
+			StepConversion: percentage(r.Visitors, previous),
+		}
+	}
+
+	return results, nil
+}
+
+type RetentionRow struct {
+	Date           string  `json:"date"`
+	Day            int     `json:"day"`
+	Visitors       int     `json:"visitors"`
+	ReturnVisitors int     `json:"returnVisitors"`
+	Percentage     float64 `json:"percentage"`
+}
+
+func (c *UmamiClient) GetRetention(
+	websiteID, startDate, endDate, timezone string, filters map[string]string,
+) ([]RetentionRow, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"timezone":  timezone,
+	}
+
+	data, err := c.runReport("retention", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []RetentionRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Percentage = percentage(rows[i].ReturnVisitors, rows[i].Visitors)
+	}
+
+	return rows, nil
+}
+
+type JourneyPath struct {
+	Steps []string `json:"steps"`
+	Count int      `json:"count"`
+}
+
+func (c *UmamiClient) GetJourney(
+	websiteID, startDate, endDate string, steps int, startStep, endStep string, filters map[string]string,
+) ([]JourneyPath, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"steps":     steps,
+	}
+	if startStep != "" {
+		parameters["startStep"] = startStep
+	}
+	if endStep != "" {
+		parameters["endStep"] = endStep
+	}
+
+	data, err := c.runReport("journey", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []struct {
+		Items []*string `json:"items"`
+		Count int       `json:"count"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	paths := make([]JourneyPath, 0, len(response))
+	for _, r := range response {
+		// Journeys shorter than the requested step count are padded with nulls
+		var stepNames []string
+		for _, item := range r.Items {
+			if item == nil {
+				break
+			}
+			stepNames = append(stepNames, *item)
+		}
+		paths = append(paths, JourneyPath{Steps: stepNames, Count: r.Count})
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return paths[i].Count > paths[j].Count
+	})
+
+	return paths, nil
+}
+
+var utmParameters = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+func (c *UmamiClient) GetUTM(
+	websiteID, startDate, endDate string, filters map[string]string,
+) (map[string][]Metric, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+	}
+
+	data, err := c.runReport("utm", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	utm := make(map[string][]Metric, len(utmParameters))
+
+	var response map[string]map[string]int
+	if err := json.Unmarshal(data, &response); err != nil {
+		var rows map[string][]struct {
+			UTM   string `json:"utm"`
+			Views int    `json:"views"`
+		}
+		if err2 := json.Unmarshal(data, &rows); err2 != nil {
+			return nil, err
+		}
+		response = make(map[string]map[string]int, len(rows))
+		for key, values := range rows {
+			response[key] = make(map[string]int, len(values))
+			for _, v := range values {
+				response[key][v.UTM] += v.Views
+			}
+		}
+	}
+
+	for _, key := range utmParameters {
+		utm[key] = sortedMetrics(response[key])
+	}
+
+	return utm, nil
+}
+
+type AttributionItem struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+type Attribution struct {
+	Referrer    []AttributionItem `json:"referrer"`
+	PaidAds     []AttributionItem `json:"paidAds"`
+	UTMSource   []AttributionItem `json:"utm_source"`
+	UTMMedium   []AttributionItem `json:"utm_medium"`
+	UTMCampaign []AttributionItem `json:"utm_campaign"`
+	UTMContent  []AttributionItem `json:"utm_content"`
+	UTMTerm     []AttributionItem `json:"utm_term"`
+	Total       struct {
+		PageViews int `json:"pageviews"`
+		Visitors  int `json:"visitors"`
+		Visits    int `json:"visits"`
+	} `json:"total"`
+}
+
+func (c *UmamiClient) GetAttribution(
+	websiteID, startDate, endDate, model, goalType, goal string, filters map[string]string,
+) (*Attribution, error) {
+	parameters := map[string]any{
+		"startDate": startDate,
+		"endDate":   endDate,
+		"model":     model,
+		"type":      goalType,
+		"step":      goal,
+	}
+
+	data, err := c.runReport("attribution", websiteID, filters, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var attribution Attribution
+	if err := json.Unmarshal(data, &attribution); err != nil {
+		return nil, err
+	}
+
+	return &attribution, nil
+}
+
+type Goal struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Target int    `json:"target"`
+}
+
+type GoalResult struct {
+	Type           string  `json:"type"`
+	Value          string  `json:"value"`
+	Target         int     `json:"target"`
+	Completions    int     `json:"completions"`
+	Visitors       int     `json:"visitors"`
+	Progress       float64 `json:"progress"`
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+func (c *UmamiClient) GetGoals(
+	websiteID, startDate, endDate string, goals []Goal, filters map[string]string,
+) ([]GoalResult, error) {
+	results := make([]GoalResult, 0, len(goals))
+	for _, goal := range goals {
+		parameters := map[string]any{
+			"startDate": startDate,
+			"endDate":   endDate,
+			"type":      goal.Type,
+			"value":     goal.Value,
+		}
+
+		data, err := c.runReport("goal", websiteID, filters, parameters)
+		if err != nil {
+			return nil, fmt.Errorf("goal %q: %w", goal.Value, err)
+		}
+
+		var response struct {
+			Num   int `json:"num"`
+			Total int `json:"total"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("goal %q: %w", goal.Value, err)
+		}
+
+		results = append(results, GoalResult{
+			Type:           goal.Type,
+			Value:          goal.Value,
+			Target:         goal.Target,
+			Completions:    response.Num,
+			Visitors:       response.Total,
+			Progress:       percentage(response.Num, goal.Target),
+			ConversionRate: percentage(response.Num, response.Total),
+		})
+	}
+
+	return results, nil
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestUmamiClient_Authenticate(t *testing.T) {
@@ -267,7 +269,7 @@ func TestUmamiClient_GetStats(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	stats, err := client.GetStats("test-website-id", "1234567890", "1234567899")
+	stats, err := client.GetStats("test-website-id", "1234567890", "1234567899", nil)
 	if err != nil {
 		t.Fatalf("GetStats failed: %v", err)
 	}
@@ -320,7 +322,7 @@ func TestUmamiClient_GetStats_WithoutComparison(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	stats, err := client.GetStats("test-website-id", "1234567890", "1234567899")
+	stats, err := client.GetStats("test-website-id", "1234567890", "1234567899", nil)
 	if err != nil {
 		t.Fatalf("GetStats failed: %v", err)
 	}
@@ -359,7 +361,7 @@ func TestUmamiClient_GetMetrics_UrlToPathMapping(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "url", 10)
+	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "url", 10, nil)
 	if err != nil {
 		t.Fatalf("GetMetrics failed: %v", err)
 	}
@@ -395,7 +397,7 @@ func TestUmamiClient_GetMetrics_DirectArray(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "path", 10)
+	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "path", 10, nil)
 	if err != nil {
 		t.Fatalf("GetMetrics failed: %v", err)
 	}
@@ -427,7 +429,7 @@ func TestUmamiClient_GetMetrics_WrappedInData(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "path", 10)
+	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "path", 10, nil)
 	if err == nil {
 		t.Error("Expected error for wrapped data format, got nil")
 	}
@@ -466,7 +468,7 @@ func TestUmamiClient_GetPageViews_ObjectResponse(t *testing.T) {
 		httpClient: &http.Client{},
 	}
 
-	pageviews, err := client.GetPageViews("test-website-id", "1234567890", "1234567899", "day")
+	pageviews, err := client.GetPageViews("test-website-id", "1234567890", "1234567899", "day", nil)
 	if err != nil {
 		t.Fatalf("GetPageViews failed: %v", err)
 	}
@@ -559,3 +561,1106 @@ func TestUmamiClient_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestUmamiClient_GetEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/event-data/events" {
+			t.Errorf("Expected event-data/events path, got %s", r.URL.Path)
+		}
+
+		if event := r.URL.Query().Get("event"); event != "signup" {
+			t.Errorf("Expected event=signup, got %s", event)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"eventName": "signup", "propertyName": "plan", "dataType": 1, "total": 42},
+			{"eventName": "signup", "propertyName": "source", "dataType": 1, "total": 40}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	events, err := client.GetEvents("test-website-id", "1234567890", "1234567899", "signup", nil)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	if events[0].EventName != "signup" || events[0].PropertyName != "plan" || events[0].Total != 42 {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+}
+
+func TestUmamiClient_GetEventSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/events/series" {
+			t.Errorf("Expected events/series path, got %s", r.URL.Path)
+		}
+
+		if unit := r.URL.Query().Get("unit"); unit != "hour" {
+			t.Errorf("Expected unit=hour, got %s", unit)
+		}
+
+		if r.URL.Query().Has("event") {
+			t.Error("Expected no event param when event name is empty")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"x": "signup", "t": "2025-01-01 10:00:00", "y": 3},
+			{"x": "checkout", "t": "2025-01-01 10:00:00", "y": 1}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	series, err := client.GetEventSeries("test-website-id", "1234567890", "1234567899", "hour", "", nil)
+	if err != nil {
+		t.Fatalf("GetEventSeries failed: %v", err)
+	}
+
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 series points, got %d", len(series))
+	}
+
+	if series[1].X != "checkout" || series[1].Y != 1 {
+		t.Errorf("Expected checkout with 1 event, got %s with %d", series[1].X, series[1].Y)
+	}
+}
+
+func TestUmamiClient_GetEventValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/event-data/values" {
+			t.Errorf("Expected event-data/values path, got %s", r.URL.Path)
+		}
+
+		query := r.URL.Query()
+		if query.Get("event") != "signup" || query.Get("propertyName") != "plan" {
+			t.Errorf("Unexpected params: event=%s, propertyName=%s", query.Get("event"), query.Get("propertyName"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"value": "pro", "total": 30},
+			{"value": "free", "total": 12}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	values, err := client.GetEventValues("test-website-id", "1234567890", "1234567899", "signup", "plan", nil)
+	if err != nil {
+		t.Fatalf("GetEventValues failed: %v", err)
+	}
+
+	if len(values) != 2 {
+		t.Fatalf("Expected 2 values, got %d", len(values))
+	}
+
+	if values[0].Value != "pro" || values[0].Total != 30 {
+		t.Errorf("Expected pro with 30, got %v with %d", values[0].Value, values[0].Total)
+	}
+}
+
+func TestUmamiClient_GetSessions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/sessions" {
+			t.Errorf("Expected sessions path, got %s", r.URL.Path)
+		}
+
+		query := r.URL.Query()
+		if query.Get("page") != "2" || query.Get("pageSize") != "5" {
+			t.Errorf("Unexpected paging params: page=%s, pageSize=%s", query.Get("page"), query.Get("pageSize"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": [{
+				"id": "a1b2c3d4-0000-0000-0000-000000000001",
+				"browser": "firefox",
+				"os": "Linux",
+				"device": "desktop",
+				"country": "DE",
+				"firstAt": "2025-01-01T10:00:00Z",
+				"lastAt": "2025-01-01T10:15:00Z",
+				"visits": 1,
+				"views": 4
+			}],
+			"count": 6,
+			"page": 2,
+			"pageSize": 5
+		}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	sessions, err := client.GetSessions("test-website-id", "1234567890", "1234567899", 2, 5, nil)
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+
+	if sessions.Count != 6 || len(sessions.Data) != 1 {
+		t.Fatalf("Expected 1 of 6 sessions, got %d of %d", len(sessions.Data), sessions.Count)
+	}
+
+	s := sessions.Data[0]
+	if s.Browser != "firefox" || s.Country != "DE" || s.Views != 4 {
+		t.Errorf("Unexpected session: %+v", s)
+	}
+
+	if !s.LastAt.After(s.FirstAt) {
+		t.Errorf("Expected lastAt after firstAt, got %v and %v", s.FirstAt, s.LastAt)
+	}
+}
+
+func TestUmamiClient_GetSessionActivity_Ordered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/sessions/test-session-id/activity" {
+			t.Errorf("Expected session activity path, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"createdAt": "2025-01-01T10:05:00Z", "urlPath": "/pricing", "eventType": 2, "eventName": "signup"},
+			{"createdAt": "2025-01-01T10:01:00Z", "urlPath": "/pricing", "eventType": 1},
+			{"createdAt": "2025-01-01T10:00:00Z", "urlPath": "/", "eventType": 1}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	activity, err := client.GetSessionActivity("test-website-id", "test-session-id", "1234567890", "1234567899")
+	if err != nil {
+		t.Fatalf("GetSessionActivity failed: %v", err)
+	}
+
+	if len(activity) != 3 {
+		t.Fatalf("Expected 3 activity entries, got %d", len(activity))
+	}
+
+	if activity[0].URLPath != "/" || activity[2].EventName != "signup" {
+		t.Errorf("Expected activity in chronological order, got %+v", activity)
+	}
+}
+
+func TestUmamiClient_GetSessionProperties(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/sessions/test-session-id/properties" {
+			t.Errorf("Expected session properties path, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"dataKey": "plan", "dataType": 1, "stringValue": "pro"},
+			{"dataKey": "seats", "dataType": 2, "numberValue": 5}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	properties, err := client.GetSessionProperties("test-website-id", "test-session-id")
+	if err != nil {
+		t.Fatalf("GetSessionProperties failed: %v", err)
+	}
+
+	if len(properties) != 2 {
+		t.Fatalf("Expected 2 properties, got %d", len(properties))
+	}
+
+	if properties[0].StringValue == nil || *properties[0].StringValue != "pro" {
+		t.Errorf("Expected plan=pro, got %+v", properties[0])
+	}
+
+	if properties[1].NumberValue == nil || *properties[1].NumberValue != 5 {
+		t.Errorf("Expected seats=5, got %+v", properties[1])
+	}
+}
+
+func TestUmamiClient_GetMetrics_Filters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("country") != "DE" || query.Get("device") != "mobile" || query.Get("path") != "/pricing" {
+			t.Errorf("Expected filters to be forwarded, got %s", r.URL.RawQuery)
+		}
+
+		if query.Get("type") != "referrer" {
+			t.Errorf("Expected filters not to override type, got %s", query.Get("type"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"x": "google.com", "y": 12}]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	filters := map[string]string{"country": "DE", "device": "mobile", "path": "/pricing", "type": "event"}
+	metrics, err := client.GetMetrics("test-website-id", "1234567890", "1234567899", "referrer", 10, filters)
+	if err != nil {
+		t.Fatalf("GetMetrics failed: %v", err)
+	}
+
+	if len(metrics) != 1 {
+		t.Errorf("Expected 1 metric, got %d", len(metrics))
+	}
+}
+
+func TestUmamiClient_GetFunnel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/funnel" {
+			t.Errorf("Expected POST /api/reports/funnel, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			WebsiteID  string `json:"websiteId"`
+			Type       string `json:"type"`
+			Parameters struct {
+				StartDate string       `json:"startDate"`
+				Window    int          `json:"window"`
+				Steps     []FunnelStep `json:"steps"`
+			} `json:"parameters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.WebsiteID != "test-website-id" || body.Type != "funnel" {
+			t.Errorf("Unexpected report body: %+v", body)
+		}
+		if body.Parameters.Window != 30 || len(body.Parameters.Steps) != 3 {
+			t.Errorf("Unexpected funnel parameters: %+v", body.Parameters)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"type": "path", "value": "/pricing", "visitors": 200},
+			{"type": "path", "value": "/signup", "visitors": 50},
+			{"type": "event", "value": "signup", "visitors": 20}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	steps := []FunnelStep{
+		{Type: "path", Value: "/pricing"},
+		{Type: "path", Value: "/signup"},
+		{Type: "event", Value: "signup"},
+	}
+	funnel, err := client.GetFunnel(
+		"test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", steps, 30, nil,
+	)
+	if err != nil {
+		t.Fatalf("GetFunnel failed: %v", err)
+	}
+
+	if len(funnel) != 3 {
+		t.Fatalf("Expected 3 steps, got %d", len(funnel))
+	}
+
+	if funnel[0].Conversion != 100 || funnel[0].Dropped != 0 {
+		t.Errorf("Expected first step at 100%% with no drop-off, got %+v", funnel[0])
+	}
+
+	if funnel[1].Dropped != 150 || funnel[1].Conversion != 25 {
+		t.Errorf("Expected 150 dropped and 25%% conversion at step 2, got %+v", funnel[1])
+	}
+
+	if funnel[2].Conversion != 10 || funnel[2].StepConversion != 40 {
+		t.Errorf("Expected 10%% overall and 40%% step conversion at step 3, got %+v", funnel[2])
+	}
+}
+
+func TestUmamiClient_GetRetention(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/retention" {
+			t.Errorf("Expected POST /api/reports/retention, got %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"date": "2025-01-06T00:00:00Z", "day": 0, "visitors": 80, "returnVisitors": 80},
+			{"date": "2025-01-06T00:00:00Z", "day": 1, "visitors": 80, "returnVisitors": 12}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	rows, err := client.GetRetention("test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", "UTC", nil)
+	if err != nil {
+		t.Fatalf("GetRetention failed: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+
+	if rows[1].Percentage != 15 {
+		t.Errorf("Expected 15%% retention on day 1, got %v", rows[1].Percentage)
+	}
+}
+
+func TestUmamiClient_GetJourney(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/journey" {
+			t.Errorf("Expected POST /api/reports/journey, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Parameters map[string]any `json:"parameters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Parameters["startStep"] != "/" {
+			t.Errorf("Expected startStep=/, got %v", body.Parameters["startStep"])
+		}
+		if _, ok := body.Parameters["endStep"]; ok {
+			t.Error("Expected no endStep when not provided")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"items": ["/", "/blog", null, null], "count": 12},
+			{"items": ["/", "/pricing", "/signup", null], "count": 30}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	paths, err := client.GetJourney(
+		"test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", 4, "/", "", nil,
+	)
+	if err != nil {
+		t.Fatalf("GetJourney failed: %v", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("Expected 2 paths, got %d", len(paths))
+	}
+
+	if paths[0].Count != 30 || len(paths[0].Steps) != 3 {
+		t.Errorf("Expected most common 3-step path first, got %+v", paths[0])
+	}
+
+	if len(paths[1].Steps) != 2 || paths[1].Steps[1] != "/blog" {
+		t.Errorf("Expected null padding to be trimmed, got %+v", paths[1])
+	}
+}
+
+func TestUmamiClient_GetUTM(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "object per parameter",
+			response: `{"utm_source": {"newsletter": 40, "twitter": 60}, "utm_medium": {"email": 40}}`,
+		},
+		{
+			name: "array per parameter",
+			response: `{"utm_source": [{"utm": "newsletter", "views": 40}, {"utm": "twitter", "views": 60}],` +
+				`"utm_medium": [{"utm": "email", "views": 40}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/reports/utm" {
+					t.Errorf("Expected POST /api/reports/utm, got %s %s", r.Method, r.URL.Path)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := &UmamiClient{
+				baseURL:    server.URL,
+				token:      "test-token",
+				httpClient: &http.Client{},
+			}
+
+			utm, err := client.GetUTM("test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", nil)
+			if err != nil {
+				t.Fatalf("GetUTM failed: %v", err)
+			}
+
+			sources := utm["utm_source"]
+			if len(sources) != 2 || sources[0].X != "twitter" || sources[0].Y != 60 {
+				t.Errorf("Expected twitter first with 60 views, got %+v", sources)
+			}
+
+			if campaigns, ok := utm["utm_campaign"]; !ok || len(campaigns) != 0 {
+				t.Errorf("Expected empty utm_campaign breakdown, got %+v", campaigns)
+			}
+		})
+	}
+}
+
+func TestUmamiClient_GetAttribution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/attribution" {
+			t.Errorf("Expected POST /api/reports/attribution, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Parameters map[string]any `json:"parameters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Parameters["model"] != "lastClick" || body.Parameters["type"] != "event" {
+			t.Errorf("Unexpected attribution parameters: %+v", body.Parameters)
+		}
+		if body.Parameters["step"] != "purchase" {
+			t.Errorf("Expected step=purchase, got %v", body.Parameters["step"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"referrer": [{"name": "google.com", "value": 14}],
+			"paidAds": [],
+			"utm_source": [{"name": "newsletter", "value": 9}],
+			"total": {"pageviews": 120, "visitors": 23, "visits": 30}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	attribution, err := client.GetAttribution(
+		"test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", "lastClick", "event", "purchase", nil,
+	)
+	if err != nil {
+		t.Fatalf("GetAttribution failed: %v", err)
+	}
+
+	if len(attribution.Referrer) != 1 || attribution.Referrer[0].Value != 14 {
+		t.Errorf("Unexpected referrer attribution: %+v", attribution.Referrer)
+	}
+
+	if attribution.Total.Visitors != 23 {
+		t.Errorf("Expected 23 converting visitors, got %d", attribution.Total.Visitors)
+	}
+}
+
+func TestUmamiClient_GetRevenue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/revenue" {
+			t.Errorf("Expected POST /api/reports/revenue, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Parameters map[string]any `json:"parameters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Parameters["currency"] != "EUR" || body.Parameters["unit"] != "day" {
+			t.Errorf("Unexpected revenue parameters: %+v", body.Parameters)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"chart": [
+				{"x": "checkout", "t": "2025-01-02", "y": 50.5},
+				{"x": "checkout", "t": "2025-01-01", "y": 100},
+				{"x": "upgrade", "t": "2025-01-01", "y": 20}
+			],
+			"country": [{"name": "DE", "value": 120}],
+			"referrer": [{"name": "google.com", "value": 70.5}],
+			"total": {"sum": 170.5, "count": 4, "unique_count": 3, "average": 42.625}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	revenue, err := client.GetRevenue(
+		"test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", "day", "UTC", "EUR", nil,
+	)
+	if err != nil {
+		t.Fatalf("GetRevenue failed: %v", err)
+	}
+
+	if revenue.Currency != "EUR" || revenue.Total.Sum != 170.5 || revenue.Total.UniqueCount != 3 {
+		t.Errorf("Unexpected revenue totals: %+v", revenue)
+	}
+
+	if len(revenue.Series) != 2 || revenue.Series[0].T != "2025-01-01" || revenue.Series[0].Y != 120 {
+		t.Errorf("Expected events summed per day in order, got %+v", revenue.Series)
+	}
+
+	if len(revenue.Countries) != 1 || len(revenue.Referrers) != 1 {
+		t.Errorf("Expected country and referrer breakdowns, got %+v", revenue)
+	}
+}
+
+func TestUmamiClient_GetGoals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/reports/goal" {
+			t.Errorf("Expected POST /api/reports/goal, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body struct {
+			Parameters struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"parameters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch body.Parameters.Value {
+		case "/thank-you":
+			_, _ = w.Write([]byte(`{"num": 30, "total": 600}`))
+		case "signup":
+			_, _ = w.Write([]byte(`{"num": 150, "total": 600}`))
+		default:
+			t.Errorf("Unexpected goal value %q", body.Parameters.Value)
+		}
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	goals := []Goal{
+		{Type: "path", Value: "/thank-you", Target: 60},
+		{Type: "event", Value: "signup", Target: 100},
+	}
+	results, err := client.GetGoals("test-website-id", "2025-01-01T00:00:00Z", "2025-01-31T00:00:00Z", goals, nil)
+	if err != nil {
+		t.Fatalf("GetGoals failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 goal results, got %d", len(results))
+	}
+
+	if results[0].Completions != 30 || results[0].Progress != 50 || results[0].ConversionRate != 5 {
+		t.Errorf("Unexpected first goal result: %+v", results[0])
+	}
+
+	if results[1].Progress != 150 || results[1].Target != 100 {
+		t.Errorf("Expected overachieved second goal at 150%%, got %+v", results[1])
+	}
+}
+
+func TestUmamiClient_GetRealtime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/realtime/test-website-id" {
+			t.Errorf("Expected realtime path, got %s", r.URL.Path)
+		}
+
+		if r.URL.Query().Get("startAt") != "1735725600000" {
+			t.Errorf("Expected startAt=1735725600000, got %s", r.URL.Query().Get("startAt"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"countries": {"DE": 2, "US": 5},
+			"urls": {"/": 4, "/pricing": 6},
+			"referrers": {"google.com": 3},
+			"events": [
+				{"__type": "pageview", "createdAt": "2025-01-01T10:01:00Z", "urlPath": "/pricing"},
+				{"__type": "event", "createdAt": "2025-01-01T10:05:00Z", "eventName": "signup"}
+			],
+			"totals": {"views": 10, "visitors": 7, "events": 1, "countries": 2}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	realtime, err := client.GetRealtime("test-website-id", time.UnixMilli(1735725600000))
+	if err != nil {
+		t.Fatalf("GetRealtime failed: %v", err)
+	}
+
+	if realtime.Totals.Visitors != 7 {
+		t.Errorf("Expected 7 visitors, got %d", realtime.Totals.Visitors)
+	}
+
+	if len(realtime.Pages) != 2 || realtime.Pages[0].X != "/pricing" {
+		t.Errorf("Expected /pricing as top page, got %+v", realtime.Pages)
+	}
+
+	if len(realtime.Events) != 2 || realtime.Events[0].Type != "event" || realtime.Events[0].EventName != "signup" {
+		t.Errorf("Expected newest event first with its type, got %+v", realtime.Events)
+	}
+}
+
+func TestUmamiClient_CreateWebsite_TeamID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/websites" {
+			t.Errorf("Expected POST /api/websites, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body["name"] != "Microsite" || body["domain"] != "micro.example.com" || body["teamId"] != "team-1" {
+			t.Errorf("Unexpected create payload: %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "new-site-id", "name": "Microsite", "domain": "micro.example.com",` +
+			`"createdAt": "2025-01-01T00:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		teamID:     "team-1",
+		httpClient: &http.Client{},
+	}
+
+	website, err := client.CreateWebsite("Microsite", "micro.example.com")
+	if err != nil {
+		t.Fatalf("CreateWebsite failed: %v", err)
+	}
+
+	if website.ID != "new-site-id" {
+		t.Errorf("Expected new-site-id, got %s", website.ID)
+	}
+}
+
+func TestUmamiClient_ResetAndDeleteWebsite(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	if err := client.ResetWebsite("test-website-id"); err != nil {
+		t.Fatalf("ResetWebsite failed: %v", err)
+	}
+	if err := client.DeleteWebsite("test-website-id"); err != nil {
+		t.Fatalf("DeleteWebsite failed: %v", err)
+	}
+
+	expected := []string{
+		"POST /api/websites/test-website-id/reset",
+		"DELETE /api/websites/test-website-id",
+	}
+	if len(requests) != 2 || requests[0] != expected[0] || requests[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, requests)
+	}
+}
+
+func TestUmamiClient_SetShareID(t *testing.T) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/websites/test-website-id" {
+			t.Errorf("Expected POST /api/websites/test-website-id, got %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		payloads = append(payloads, body)
+
+		shareID, _ := body["shareId"].(string)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "test-website-id", "shareId": shareID})
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	website, err := client.SetShareID("test-website-id", "abcDEF1234567890")
+	if err != nil {
+		t.Fatalf("SetShareID failed: %v", err)
+	}
+	if website.ShareID != "abcDEF1234567890" {
+		t.Errorf("Expected share ID abcDEF1234567890, got %s", website.ShareID)
+	}
+	if url := client.ShareURL(website.ShareID); url != server.URL+"/share/abcDEF1234567890" {
+		t.Errorf("Unexpected share URL %s", url)
+	}
+
+	if _, err := client.SetShareID("test-website-id", ""); err != nil {
+		t.Fatalf("SetShareID failed: %v", err)
+	}
+	if value, ok := payloads[1]["shareId"]; !ok || value != nil {
+		t.Errorf("Expected shareId null when disabling, got %v", payloads[1])
+	}
+}
+
+func TestGenerateShareID(t *testing.T) {
+	id := generateShareID()
+	if len(id) != 16 {
+		t.Fatalf("Expected 16 characters, got %q", id)
+	}
+	for _, r := range id {
+		if !strings.ContainsRune(shareIDChars, r) {
+			t.Errorf("Unexpected character %q in %s", r, id)
+		}
+	}
+	if id == generateShareID() {
+		t.Error("Expected distinct share IDs")
+	}
+}
+
+func TestGenerateShareID_Unbiased(t *testing.T) {
+	counts := make(map[rune]int)
+	for range 4000 {
+		for _, r := range generateShareID() {
+			counts[r]++
+		}
+	}
+
+	// A modulo mapping of raw bytes favours the first 256%62 = 8 characters by 25%.
+	favoured, rest := 0, 0
+	for i, r := range shareIDChars {
+		if i < 256%len(shareIDChars) {
+			favoured += counts[r]
+		} else {
+			rest += counts[r]
+		}
+	}
+	perFavoured := float64(favoured) / 8
+	perRest := float64(rest) / float64(len(shareIDChars)-8)
+	if perFavoured > perRest*1.05 {
+		t.Errorf("Leading characters are over-represented: %.0f vs %.0f per character", perFavoured, perRest)
+	}
+}
+
+func TestUmamiClient_GetTeams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/me/teams" {
+			t.Errorf("Expected path /api/me/teams, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "team-1", "name": "Marketing", "createdAt": "2025-01-01T00:00:00Z",` +
+			`"_count": {"website": 12, "teamUser": 4}}], "count": 1}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	teams, err := client.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams failed: %v", err)
+	}
+
+	if len(teams) != 1 || teams[0].Name != "Marketing" || teams[0].Websites != 12 || teams[0].Members != 4 {
+		t.Errorf("Unexpected teams: %+v", teams)
+	}
+}
+
+func TestUmamiClient_GetTeamMembers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/teams/team-1/users" {
+			t.Errorf("Expected path /api/teams/team-1/users, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "tu-1", "teamId": "team-1", "userId": "user-1",` +
+			`"role": "team-owner", "user": {"id": "user-1", "username": "alice"}}]}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	members, err := client.GetTeamMembers("team-1")
+	if err != nil {
+		t.Fatalf("GetTeamMembers failed: %v", err)
+	}
+
+	expected := TeamMember{UserID: "user-1", Username: "alice", Role: "team-owner"}
+	if len(members) != 1 || members[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, members)
+	}
+}
+
+func TestUmamiClient_GetUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/admin/users" {
+			t.Errorf("Expected path /api/admin/users, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "user-1", "username": "admin", "role": "admin",` +
+			`"createdAt": "2025-01-01T00:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	users, err := client.GetUsers()
+	if err != nil {
+		t.Fatalf("GetUsers failed: %v", err)
+	}
+
+	if len(users) != 1 || users[0].Username != "admin" || users[0].Role != "admin" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+}
+
+func TestUmamiClient_GetSegments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/segments" {
+			t.Errorf("Expected segments path, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("type") != "cohort" {
+			t.Errorf("Expected type=cohort, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "seg-1", "type": "cohort", "name": "Paying customers",` +
+			`"parameters": {"filters": [{"name": "event", "operator": "eq", "value": "checkout"}]},` +
+			`"createdAt": "2025-01-01T00:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	segments, err := client.GetSegments("test-website-id", "cohort")
+	if err != nil {
+		t.Fatalf("GetSegments failed: %v", err)
+	}
+
+	if len(segments) != 1 || segments[0].Name != "Paying customers" || segments[0].Type != "cohort" {
+		t.Fatalf("Unexpected segments: %+v", segments)
+	}
+	if len(segments[0].Parameters) == 0 {
+		t.Error("Expected segment parameters to be kept")
+	}
+}
+
+func TestUmamiClient_GetSessionDataValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/websites/test-website-id/session-data/values" {
+			t.Errorf("Expected session-data values path, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("propertyName") != "plan" {
+			t.Errorf("Expected propertyName=plan, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"value": "pro", "total": 42}, {"value": "free", "total": 108}]`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	values, err := client.GetSessionDataValues("test-website-id", "1234567890", "1234567899", "plan", nil)
+	if err != nil {
+		t.Fatalf("GetSessionDataValues failed: %v", err)
+	}
+
+	if len(values) != 2 || values[0].Value != "pro" || values[0].Total != 42 {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+}
+
+func TestUmamiClient_GetSessionsByDistinctID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("distinctId") != "user-42" {
+			t.Errorf("Expected distinctId=user-42, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [{"id": "s1", "distinctId": "user-42"}, {"id": "s2", "distinctId": "user-7"},` +
+			`{"id": "s3"}], "count": 3, "page": 1, "pageSize": 5}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	sessions, err := client.GetSessionsByDistinctID("test-website-id", "user-42", "1234567890", "1234567899", 5)
+	if err != nil {
+		t.Fatalf("GetSessionsByDistinctID failed: %v", err)
+	}
+
+	if len(sessions) != 1 || sessions[0].ID != "s1" {
+		t.Errorf("Expected only the session of user-42, got %+v", sessions)
+	}
+}
+
+func TestUmamiClient_GetTrafficSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("unit") != "hour" || query.Get("timezone") != "Europe/Berlin" {
+			t.Errorf("Expected hourly series in Europe/Berlin, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pageviews": [{"x": "2025-01-06 09:00:00", "y": 12}],` +
+			`"sessions": [{"x": "2025-01-06 09:00:00", "y": 4}]}`))
+	}))
+	defer server.Close()
+
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		token:      "test-token",
+		httpClient: &http.Client{},
+	}
+
+	series, err := client.GetTrafficSeries("test-website-id", "1234567890", "1234567899", "hour", "Europe/Berlin", nil)
+	if err != nil {
+		t.Fatalf("GetTrafficSeries failed: %v", err)
+	}
+
+	if len(series.PageViews) != 1 || series.PageViews[0].Y != 12 {
+		t.Errorf("Unexpected pageviews: %+v", series.PageViews)
+	}
+	if len(series.Sessions) != 1 || series.Sessions[0].Y != 4 {
+		t.Fatalf("Unexpected sessions: %+v", series.Sessions)
+	}
+	if series.Sessions[0].time() != "2025-01-06 09:00:00" {
+		t.Errorf("Expected x label to be used, got %q", series.Sessions[0].time())
+	}
+}
+
+func TestUmamiClient_ProbeFailuresLoggedAtDebug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var logs []string
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		apiKey:     "key",
+		httpClient: &http.Client{},
+		logger: func(level, message string) {
+			logs = append(logs, level+": "+message)
+		},
+	}
+
+	if _, err := client.GetSessionProperties("abc123", "def456"); err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+
+	if len(logs) != 1 || !strings.HasPrefix(logs[0], "debug: ") {
+		t.Errorf("Expected the missing properties endpoint to be logged at debug, got %v", logs)
+	}
+}
+
+func TestUmamiClient_LogsUpstreamErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var logs []string
+	client := &UmamiClient{
+		baseURL:    server.URL,
+		apiKey:     "key",
+		httpClient: &http.Client{},
+		logger: func(level, message string) {
+			logs = append(logs, level+": "+message)
+		},
+	}
+
+	if _, err := client.GetWebsites(false); err == nil {
+		t.Fatal("Expected error for 502 response")
+	}
+
+	if len(logs) != 1 || logs[0] != "error: Umami GET /api/websites returned status 502" {
+		t.Errorf("Unexpected logs: %v", logs)
+	}
+}
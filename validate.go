@@ -1,15 +1,119 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
 
 func validateWebsiteID(id string) error {
-	if id == "" || len(id) > 36 {
+	if !isHexID(id) {
 		return fmt.Errorf("invalid website ID")
 	}
+	return nil
+}
+
+func validateSessionID(id string) error {
+	if !isHexID(id) {
+		return fmt.Errorf("invalid session ID")
+	}
+	return nil
+}
+
+func validateTeamID(id string) error {
+	if !isHexID(id) {
+		return fmt.Errorf("invalid team ID")
+	}
+	return nil
+}
+
+func validateCurrency(code string) error {
+	if len(code) != 3 {
+		return fmt.Errorf("invalid currency code")
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return fmt.Errorf("invalid currency code")
+		}
+	}
+	return nil
+}
+
+// isStepType reports whether t names a report step kind: a page path or a custom event.
+func isStepType(t string) bool {
+	return t == metricTypePath || t == "event"
+}
+
+func isHexID(id string) bool {
+	if id == "" || len(id) > 36 {
+		return false
+	}
 	for _, c := range id {
 		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') || c == '-') {
-			return fmt.Errorf("invalid website ID")
+			return false
 		}
 	}
+	return true
+}
+
+// filterKeys lists the Umami query parameters accepted in a tool's filters object.
+var filterKeys = []string{
+	"path", "referrer", "title", "query", "browser", "os", "device",
+	"country", "region", "city", "hostname", "tag", "event",
+	"utm_source", "utm_medium", "utm_campaign", "utm_content", "utm_term",
+	"segment", "cohort",
+}
+
+func validateFilters(filters map[string]string) error {
+	for key, value := range filters {
+		if !slices.Contains(filterKeys, key) {
+			return fmt.Errorf("unknown filter %q (allowed: %s)", key, strings.Join(filterKeys, ", "))
+		}
+		if value == "" {
+			return fmt.Errorf("empty value for filter %q", key)
+		}
+	}
+	return nil
+}
+
+// analyticsFilters holds the narrowing arguments shared by the analytics tools.
+type analyticsFilters struct {
+	Filters   map[string]string `json:"filters"`
+	SegmentID string            `json:"segment_id"`
+}
+
+// resolveFilters validates the filters and folds segment_id into them so the
+// client forwards it like any other filter. Umami keeps segments and cohorts
+// under separate filter keys, so segmentType reports which kind the saved
+// entry is.
+func (f *analyticsFilters) resolveFilters(segmentType func(segmentID string) (string, error)) error {
+	if err := validateFilters(f.Filters); err != nil {
+		return err
+	}
+	if f.SegmentID == "" {
+		return nil
+	}
+	if !isHexID(f.SegmentID) {
+		return fmt.Errorf("invalid segment_id")
+	}
+
+	typ, err := segmentType(f.SegmentID)
+	if err != nil {
+		return fmt.Errorf("segment_id %s: %w", f.SegmentID, err)
+	}
+	key := "segment"
+	if typ == "cohort" {
+		key = "cohort"
+	}
+	if existing, ok := f.Filters[key]; ok && existing != f.SegmentID {
+		return fmt.Errorf("segment_id conflicts with filters.%s", key)
+	}
+
+	filters := make(map[string]string, len(f.Filters)+1)
+	for k, v := range f.Filters {
+		filters[k] = v
+	}
+	filters[key] = f.SegmentID
+	f.Filters = filters
 	return nil
 }
@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestValidateWebsiteID(t *testing.T) {
 	tests := []struct {
@@ -29,3 +32,85 @@ func TestValidateWebsiteID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSessionID(t *testing.T) {
+	if err := validateSessionID("550e8400-e29b-41d4-a716-446655440000"); err != nil {
+		t.Errorf("Expected valid session ID, got %v", err)
+	}
+	if err := validateSessionID("../sessions"); err == nil {
+		t.Error("Expected error for path traversal session ID")
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]string
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"known keys", map[string]string{"country": "DE", "device": "mobile", "utm_source": "newsletter"}, false},
+		{"segment", map[string]string{"segment": "550e8400-e29b-41d4-a716-446655440000"}, false},
+		{"unknown key", map[string]string{"startAt": "0"}, true},
+		{"empty value", map[string]string{"path": ""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFilters(tt.filters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFilters(%v) error = %v, wantErr %v", tt.filters, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	for _, code := range []string{"USD", "EUR", "JPY"} {
+		if err := validateCurrency(code); err != nil {
+			t.Errorf("validateCurrency(%q) returned %v", code, err)
+		}
+	}
+	for _, code := range []string{"", "usd", "EURO", "U$D"} {
+		if err := validateCurrency(code); err == nil {
+			t.Errorf("validateCurrency(%q) should fail", code)
+		}
+	}
+}
+
+func TestResolveFilters(t *testing.T) {
+	segmentID := "550e8400-e29b-41d4-a716-446655440000"
+	isSegment := func(string) (string, error) { return "segment", nil }
+	isCohort := func(string) (string, error) { return "cohort", nil }
+
+	f := analyticsFilters{Filters: map[string]string{"country": "DE"}, SegmentID: segmentID}
+	if err := f.resolveFilters(isSegment); err != nil {
+		t.Fatalf("resolveFilters failed: %v", err)
+	}
+	if f.Filters["segment"] != segmentID || f.Filters["country"] != "DE" {
+		t.Errorf("Expected segment_id folded into filters, got %v", f.Filters)
+	}
+
+	f = analyticsFilters{SegmentID: segmentID}
+	if err := f.resolveFilters(isCohort); err != nil {
+		t.Fatalf("resolveFilters failed: %v", err)
+	}
+	if f.Filters["cohort"] != segmentID || f.Filters["segment"] != "" {
+		t.Errorf("Expected cohort ID sent as filters.cohort, got %v", f.Filters)
+	}
+
+	f = analyticsFilters{SegmentID: segmentID}
+	if err := f.resolveFilters(func(string) (string, error) { return "", errors.New("not found") }); err == nil {
+		t.Error("Expected error when the saved segment cannot be looked up")
+	}
+
+	f = analyticsFilters{SegmentID: "../segments"}
+	if err := f.resolveFilters(isSegment); err == nil {
+		t.Error("Expected error for invalid segment_id")
+	}
+
+	f = analyticsFilters{Filters: map[string]string{"segment": "abc123"}, SegmentID: segmentID}
+	if err := f.resolveFilters(isSegment); err == nil {
+		t.Error("Expected error when segment_id conflicts with filters.segment")
+	}
+}